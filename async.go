@@ -0,0 +1,194 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned when a record is passed to a closed handler.
+var ErrClosed = errors.New("log: handler is closed")
+
+const defaultQueueSize = 1024
+
+// OverflowPolicy defines what AsyncHandler does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the logger until there is a room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the new record.
+	OverflowDropNewest
+	// OverflowDropLowest drops the oldest record of the lowest level, e.g. trace before debug,
+	// the new record is dropped when its level is the lowest.
+	OverflowDropLowest
+)
+
+// AsyncOpts configures AsyncHandler.
+type AsyncOpts struct {
+	// QueueSize is a maximum number of records waiting to be handled.
+	//
+	//	Default: 1024
+	QueueSize int
+	// Overflow defines what happens when the queue is full.
+	//
+	//	Default: OverflowBlock
+	Overflow OverflowPolicy
+}
+
+// AsyncHandler passes records to the handler in background, so the logger does not wait for slow writers.
+//
+// Records are kept in a bounded queue, when the queue is full, records are dropped or the logger is blocked
+// depending on the overflow policy. Flush waits for the queued records to be handled,
+// Close handles the queued records and stops the background goroutine.
+type AsyncHandler struct {
+	handler Handler
+	opts    AsyncOpts
+
+	mu       sync.Mutex
+	changed  *sync.Cond
+	queue    []Record
+	inFlight int
+	closed   bool
+	done     chan struct{}
+
+	dropped [LevelTrace + 1]atomic.Uint64
+}
+
+// NewAsyncHandler creates AsyncHandler and starts a background goroutine passing records to the handler.
+func NewAsyncHandler(handler Handler, opts AsyncOpts) *AsyncHandler {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	h := &AsyncHandler{
+		handler: handler,
+		opts:    opts,
+		queue:   make([]Record, 0, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+	h.changed = sync.NewCond(&h.mu)
+	go h.run()
+	return h
+}
+
+// Handle puts the record to the queue.
+func (h *AsyncHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for !h.closed && len(h.queue) >= h.opts.QueueSize && h.opts.Overflow == OverflowBlock {
+		h.changed.Wait()
+	}
+	if h.closed {
+		return ErrClosed
+	}
+	if len(h.queue) >= h.opts.QueueSize {
+		if h.opts.Overflow != OverflowDropLowest || !h.dropLowest(r.Level) {
+			h.drop(r.Level)
+			return nil
+		}
+	}
+	h.queue = append(h.queue, r)
+	h.changed.Broadcast()
+	return nil
+}
+
+// dropLowest removes the oldest record of the lowest level from the queue,
+// if the level is lower than the level of the new record.
+func (h *AsyncHandler) dropLowest(level Level) bool {
+	index := -1
+	for i := range h.queue {
+		if h.queue[i].Level > level && (index < 0 || h.queue[i].Level > h.queue[index].Level) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	h.drop(h.queue[index].Level)
+	h.queue = append(h.queue[:index], h.queue[index+1:]...)
+	return true
+}
+
+func (h *AsyncHandler) drop(level Level) {
+	h.dropped[normalizeLevel(level)].Add(1)
+}
+
+// Dropped returns the number of dropped records.
+func (h *AsyncHandler) Dropped() uint64 {
+	var dropped uint64
+	for level := LevelFatal; level <= LevelTrace; level++ {
+		dropped += h.dropped[level].Load()
+	}
+	return dropped
+}
+
+// DroppedLevel returns the number of dropped records of the level.
+func (h *AsyncHandler) DroppedLevel(level Level) uint64 {
+	return h.dropped[normalizeLevel(level)].Load()
+}
+
+// Flush waits for all the queued records to be handled.
+func (h *AsyncHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for len(h.queue) > 0 || h.inFlight > 0 {
+		h.changed.Wait()
+	}
+	return nil
+}
+
+// Sync waits for all the queued records to be handled and syncs the handler.
+func (h *AsyncHandler) Sync() error {
+	h.Flush()
+	return syncHandler(h.handler)
+}
+
+// Close handles the queued records, stops the background goroutine and closes the handler,
+// records passed after Close are rejected with ErrClosed.
+func (h *AsyncHandler) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrClosed
+	}
+	h.closed = true
+	h.changed.Broadcast()
+	h.mu.Unlock()
+
+	<-h.done
+	return closeHandler(h.handler)
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.done)
+
+	batch := make([]Record, 0, h.opts.QueueSize)
+	for {
+		h.mu.Lock()
+		for len(h.queue) == 0 && !h.closed {
+			h.changed.Wait()
+		}
+		if len(h.queue) == 0 && h.closed {
+			h.mu.Unlock()
+			return
+		}
+		batch = append(batch[:0], h.queue...)
+		clear(h.queue)
+		h.queue = h.queue[:0]
+		h.inFlight = len(batch)
+		h.changed.Broadcast()
+		h.mu.Unlock()
+
+		for i := range batch {
+			h.handler.Handle(batch[i])
+		}
+		clear(batch)
+
+		h.mu.Lock()
+		h.inFlight = 0
+		h.changed.Broadcast()
+		h.mu.Unlock()
+	}
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// blockingHandler handles records only when the gate is open.
+type blockingHandler struct {
+	testHandler
+	gate chan struct{}
+}
+
+func (h *blockingHandler) Handle(r Record) error {
+	<-h.gate
+	return h.testHandler.Handle(r)
+}
+
+// waitInFlight waits for the background goroutine to take the records from the queue.
+func waitInFlight(t *testing.T, h *AsyncHandler) {
+	t.Helper()
+
+	for i := 0; i < 1000; i++ {
+		h.mu.Lock()
+		inFlight := h.inFlight
+		h.mu.Unlock()
+		if inFlight > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("records were not taken from the queue")
+}
+
+func messages(records []Record) []string {
+	result := make([]string, 0, len(records))
+	for _, r := range records {
+		result = append(result, r.Message)
+	}
+	return result
+}
+
+func Test_AsyncHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		overflow OverflowPolicy
+		levels   []Level
+		expected []string
+		dropped  map[Level]uint64
+	}{
+		{
+			name:     "drop-newest",
+			overflow: OverflowDropNewest,
+			levels:   []Level{LevelDebug, LevelInfo, LevelError},
+			expected: []string{"0", "1", "2"},
+			dropped:  map[Level]uint64{LevelError: 1},
+		},
+		{
+			name:     "drop-lowest",
+			overflow: OverflowDropLowest,
+			levels:   []Level{LevelInfo, LevelDebug, LevelError, LevelTrace, LevelWarn},
+			expected: []string{"0", "3", "5"},
+			dropped:  map[Level]uint64{LevelDebug: 1, LevelTrace: 1, LevelInfo: 1},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &blockingHandler{gate: make(chan struct{})}
+			h := NewAsyncHandler(handler, AsyncOpts{QueueSize: 2, Overflow: test.overflow})
+			h.Handle(Record{Level: LevelInfo, Message: "0"})
+			waitInFlight(t, h)
+			for i, level := range test.levels {
+				h.Handle(Record{Level: level, Message: string(rune('1' + i))})
+			}
+			close(handler.gate)
+			h.Flush()
+
+			result := messages(handler.records)
+			if len(result) != len(test.expected) {
+				t.Fatalf("expected %v, but got %v", test.expected, result)
+			}
+			for i := range result {
+				if result[i] != test.expected[i] {
+					t.Errorf("expected %v, but got %v", test.expected, result)
+				}
+			}
+			var total uint64
+			for level, dropped := range test.dropped {
+				total += dropped
+				if h.DroppedLevel(level) != dropped {
+					t.Errorf("dropped %d level expected %d, but got %d", level, dropped, h.DroppedLevel(level))
+				}
+			}
+			if h.Dropped() != total {
+				t.Errorf("dropped expected %d, but got %d", total, h.Dropped())
+			}
+			h.Close()
+		})
+	}
+}
+
+func Test_AsyncHandler_block(t *testing.T) {
+	t.Parallel()
+
+	handler := &blockingHandler{gate: make(chan struct{})}
+	h := NewAsyncHandler(handler, AsyncOpts{QueueSize: 1})
+	h.Handle(Record{Message: "0"})
+	waitInFlight(t, h)
+	h.Handle(Record{Message: "1"})
+
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		h.Handle(Record{Message: "2"})
+	}()
+	select {
+	case <-handled:
+		t.Fatalf("handle expected to be blocked while the queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(handler.gate)
+	<-handled
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if result := messages(handler.records); len(result) != 3 {
+		t.Errorf("all the records expected to be handled on close, but got %v", result)
+	}
+	if err := h.Handle(Record{}); err != ErrClosed {
+		t.Errorf("handle after close expected %v, but got %v", ErrClosed, err)
+	}
+	if h.Dropped() != 0 {
+		t.Errorf("no records expected to be dropped, but got %d", h.Dropped())
+	}
+}
+
+func Test_Async(t *testing.T) {
+	t.Parallel()
+
+	handler := &testHandler{}
+	l := New(CustomHandler(handler), Async(AsyncOpts{}))
+	for i := 0; i < 100; i++ {
+		l.Info("message")
+	}
+	async, ok := l.(*logger).handler.(*AsyncHandler)
+	if !ok {
+		t.Fatalf("async handler expected, but got %T", l.(*logger).handler)
+	}
+	async.Flush()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.records) != 100 {
+		t.Errorf("expected %d records, but got %d", 100, len(handler.records))
+	}
+}
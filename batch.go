@@ -0,0 +1,315 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBatchSize       = 100
+	defaultBatchInterval   = time.Second
+	defaultBatchMaxPending = 10000
+
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// BatchOpts configures batching of records sent over network, zero values are replaced by defaults.
+type BatchOpts struct {
+	// Size is the maximum number of records sent at once, the batch is sent as soon as it is full.
+	//
+	//	Default: 100
+	Size int
+	// Interval is the maximum time a record waits for the batch to be sent.
+	//
+	//	Default: 1s
+	Interval time.Duration
+	// MaxPending is the maximum number of records waiting to be sent, e.g. while the server is unavailable,
+	// newer records are dropped.
+	//
+	//	Default: 10000
+	MaxPending int
+}
+
+// RetryOpts configures retries of batches which failed to be sent, zero values are replaced by defaults.
+//
+// The delay between retries is doubled after each retry, it is randomized to spread retries of many loggers.
+type RetryOpts struct {
+	// MaxRetries is the maximum number of retries of a batch, negative value turns retries off.
+	//
+	//	Default: 3
+	MaxRetries int
+	// MinBackoff is the delay before the first retry.
+	//
+	//	Default: 500ms
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries.
+	//
+	//	Default: 30s
+	MaxBackoff time.Duration
+}
+
+// backoff returns the delay before the retry, retries are counted from zero.
+func (o RetryOpts) backoff(retry int) time.Duration {
+	d := o.MaxBackoff
+	if retry < 32 {
+		d = min(o.MinBackoff<<retry, o.MaxBackoff)
+	}
+	// from a half to the full delay
+	return d/2 + rand.N(d/2+1)
+}
+
+// permanentError is an error of sending, which is not fixed by retries, e.g. a rejected request.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// partialError is an error of sending, when only some of the records failed and only they should be retried.
+type partialError struct {
+	err error
+	// failed are indexes of the failed records in the batch.
+	failed []int
+	// rejected is the number of records, which are not retried.
+	rejected int
+}
+
+func (e *partialError) Error() string { return e.err.Error() }
+func (e *partialError) Unwrap() error { return e.err }
+
+// batcher collects records and passes them to the send function in background,
+// when the batch is full, the interval elapsed or flush is called.
+//
+// Batches are sent one by one, so the send function is not called concurrently.
+type batcher struct {
+	opts  BatchOpts
+	retry RetryOpts
+	send  func([]Record) error
+
+	mu       sync.Mutex
+	changed  *sync.Cond
+	pending  []Record
+	inFlight int
+	err      error
+	closed   bool
+
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// newBatcher creates a batcher, send errors wrapped by permanentError are not retried,
+// only the failed records are retried for partialError.
+func newBatcher(opts BatchOpts, retry RetryOpts, send func([]Record) error) *batcher {
+	if opts.Size <= 0 {
+		opts.Size = defaultBatchSize
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultBatchInterval
+	}
+	if opts.MaxPending < opts.Size {
+		opts.MaxPending = max(defaultBatchMaxPending, opts.Size)
+	}
+	if retry.MaxRetries == 0 {
+		retry.MaxRetries = defaultMaxRetries
+	}
+	if retry.MinBackoff <= 0 {
+		retry.MinBackoff = defaultMinBackoff
+	}
+	if retry.MaxBackoff < retry.MinBackoff {
+		retry.MaxBackoff = max(defaultMaxBackoff, retry.MinBackoff)
+	}
+	b := &batcher{
+		opts:  opts,
+		retry: retry,
+		send:  send,
+		wake:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	b.changed = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// handle adds the record to the pending records, the record is dropped, when there are too many of them.
+func (b *batcher) handle(r Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if len(b.pending) >= b.opts.MaxPending {
+		b.dropped.Add(1)
+		return nil
+	}
+	b.pending = append(b.pending, r)
+	if len(b.pending) >= b.opts.Size {
+		b.notify()
+	}
+	return nil
+}
+
+// flush waits for the pending records to be sent and returns the last error of sending since the previous flush.
+func (b *batcher) flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for !b.closed && (len(b.pending) > 0 || b.inFlight > 0) {
+		b.notify()
+		b.changed.Wait()
+	}
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// close sends the pending records and stops the background goroutine.
+func (b *batcher) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.closed = true
+	b.changed.Broadcast()
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// notify wakes the background goroutine up, it does not block, when the goroutine is already woken.
+func (b *batcher) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.wake:
+		case <-ticker.C:
+		case <-b.stop:
+			b.sendPending()
+			return
+		}
+		b.sendPending()
+	}
+}
+
+// sendPending sends the pending records by batches until there are no pending records.
+func (b *batcher) sendPending() {
+	batch := make([]Record, 0, b.opts.Size)
+	for {
+		b.mu.Lock()
+		if len(b.pending) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		n := min(len(b.pending), b.opts.Size)
+		batch = append(batch[:0], b.pending[:n]...)
+		rest := copy(b.pending, b.pending[n:])
+		clear(b.pending[rest:])
+		b.pending = b.pending[:rest]
+		b.inFlight = n
+		b.mu.Unlock()
+
+		err := b.sendWithRetry(batch)
+		clear(batch)
+
+		b.mu.Lock()
+		if err != nil {
+			b.err = err
+		}
+		b.inFlight = 0
+		b.changed.Broadcast()
+		b.mu.Unlock()
+	}
+}
+
+// sendWithRetry sends the batch retrying it after a delay, retries are stopped, when the batcher is closed.
+func (b *batcher) sendWithRetry(batch []Record) error {
+	err := b.send(batch)
+	// rejected is reported, even when the failed records are sent by a retry
+	var rejected error
+	var permanent *permanentError
+	for retry := 0; err != nil && retry < b.retry.MaxRetries && !errors.As(err, &permanent); retry++ {
+		var partial *partialError
+		if errors.As(err, &partial) {
+			if partial.rejected > 0 {
+				rejected = err
+			}
+			failed := make([]Record, 0, len(partial.failed))
+			for _, i := range partial.failed {
+				failed = append(failed, batch[i])
+			}
+			batch = failed
+		}
+		timer := time.NewTimer(b.retry.backoff(retry))
+		select {
+		case <-timer.C:
+		case <-b.stop:
+			timer.Stop()
+			return err
+		}
+		err = b.send(batch)
+	}
+	if errors.As(err, &permanent) {
+		return permanent.err
+	}
+	if err == nil {
+		return rejected
+	}
+	return err
+}
+
+// doRequest sends the request and decodes JSON response body to v, when it is not nil,
+// errors of requests rejected with 4xx status except 429 are permanent.
+func doRequest(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if v == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("log: %s %s: invalid response: %w", req.Method, req.URL.Redacted(), err)
+		}
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("log: %s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSender collects batches sent by a batcher.
+type testSender struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (s *testSender) send(records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := make([]string, 0, len(records))
+	for _, r := range records {
+		batch = append(batch, r.Message)
+	}
+	s.batches = append(s.batches, batch)
+	return s.err
+}
+
+func (s *testSender) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sizes := make([]int, 0, len(s.batches))
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func Test_batcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     BatchOpts
+		records  int
+		flush    bool
+		expected []int
+	}{
+		{
+			name:     "full-batches",
+			opts:     BatchOpts{Size: 2, Interval: time.Hour},
+			records:  4,
+			flush:    false,
+			expected: []int{2, 2},
+		},
+		{
+			name:     "flush",
+			opts:     BatchOpts{Size: 10, Interval: time.Hour},
+			records:  3,
+			flush:    true,
+			expected: []int{3},
+		},
+		{
+			name:     "interval",
+			opts:     BatchOpts{Size: 10, Interval: 10 * time.Millisecond},
+			records:  3,
+			flush:    false,
+			expected: []int{3},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			sender := &testSender{}
+			b := newBatcher(test.opts, RetryOpts{}, sender.send)
+			defer b.close()
+			for i := 0; i < test.records; i++ {
+				if err := b.handle(Record{Message: "message"}); err != nil {
+					t.Fatalf("failed to handle: %s", err)
+				}
+			}
+			if test.flush {
+				if err := b.flush(); err != nil {
+					t.Fatalf("failed to flush: %s", err)
+				}
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(sender.sizes()) < len(test.expected) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			sizes := sender.sizes()
+			if len(sizes) != len(test.expected) {
+				t.Fatalf("batches expected %v, but got %v", test.expected, sizes)
+			}
+			for i := range sizes {
+				if sizes[i] != test.expected[i] {
+					t.Errorf("batches expected %v, but got %v", test.expected, sizes)
+				}
+			}
+		})
+	}
+}
+
+func Test_batcher_errors(t *testing.T) {
+	t.Parallel()
+
+	sendErr := errors.New("send failed")
+	sender := &testSender{err: sendErr}
+	b := newBatcher(BatchOpts{Size: 1, Interval: time.Hour}, RetryOpts{MaxRetries: -1}, sender.send)
+	_ = b.handle(Record{Message: "message"})
+	if err := b.flush(); !errors.Is(err, sendErr) {
+		t.Errorf("error expected %v, but got %v", sendErr, err)
+	}
+	if err := b.flush(); err != nil {
+		t.Errorf("error is expected to be returned once, but got %v", err)
+	}
+	if err := b.close(); err != nil {
+		t.Errorf("failed to close: %s", err)
+	}
+	if err := b.handle(Record{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("error expected %v, but got %v", ErrClosed, err)
+	}
+	if err := b.close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("error expected %v, but got %v", ErrClosed, err)
+	}
+}
+
+func Test_batcher_maxPending(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	sender := &testSender{}
+	b := newBatcher(BatchOpts{Size: 1, Interval: time.Hour, MaxPending: 2}, RetryOpts{}, func(records []Record) error {
+		<-release
+		return sender.send(records)
+	})
+	for i := 0; i < 10; i++ {
+		_ = b.handle(Record{Message: "message"})
+	}
+	close(release)
+	if err := b.close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+
+	// the first record may be taken by the sender before the others are added
+	sent, dropped := len(sender.sizes()), int(b.dropped.Load())
+	if sent+dropped != 10 || sent > 3 {
+		t.Errorf("up to 3 sent records expected and the rest dropped, but got %d sent and %d dropped", sent, dropped)
+	}
+}
+
+func Test_RetryOpts_backoff(t *testing.T) {
+	t.Parallel()
+
+	opts := RetryOpts{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		retry    int
+		expected time.Duration
+	}{
+		{retry: 0, expected: 100 * time.Millisecond},
+		{retry: 1, expected: 200 * time.Millisecond},
+		{retry: 3, expected: 800 * time.Millisecond},
+		{retry: 4, expected: time.Second},
+		{retry: 100, expected: time.Second},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.expected.String(), func(t *testing.T) {
+			t.Parallel()
+
+			if d := opts.backoff(test.retry); d < test.expected/2 || d > test.expected {
+				t.Errorf("backoff of retry %d expected between %s and %s, but got %s", test.retry, test.expected/2, test.expected, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errElasticInvalidResponse is returned, when the bulk response does not match the request.
+var errElasticInvalidResponse = errors.New("log: invalid bulk response")
+
+const (
+	// ECSVersion is the version of Elastic Common Schema of the documents.
+	ECSVersion = "8.11.0"
+
+	defaultElasticIndex   = "logs"
+	defaultElasticTimeout = 10 * time.Second
+	elasticTimeFormat     = "2006-01-02T15:04:05.000000Z"
+)
+
+// ElasticOpts configures an ElasticHandler, zero values are replaced by defaults.
+type ElasticOpts struct {
+	// Index is the index or the data stream of the documents.
+	//
+	//	Default: logs
+	Index string
+	// Header is added to the requests, e.g. `Authorization: ApiKey ...`.
+	Header http.Header
+	// Gzip compresses the requests.
+	Gzip bool
+	// Client sends the requests.
+	//
+	//	Default: http.Client with 10s timeout
+	Client *http.Client
+	// Batch configures batching of the records.
+	Batch BatchOpts
+	// Retry configures retries of the documents which failed to be indexed,
+	// documents rejected with 4xx status except 429 are not retried.
+	Retry RetryOpts
+}
+
+// ElasticHandler is a Handler that indexes records to Elasticsearch or OpenSearch by the bulk API
+// as Elastic Common Schema documents:
+//
+//	{"@timestamp":"2025-03-22T14:07:50.348957Z","log.level":"error","message":"failed","labels":{"user":"1000"},"ecs.version":"8.11.0"}
+//
+// The logger name is sent as log.logger, the caller as log.origin fields, the error of Err field as error.message.
+// Fields of ECS field sets are sent as they are, e.g. `http.request.method`, other labels and fields are sent
+// under labels as strings, e.g. `user=1000` -> labels.user, so they do not conflict with ECS mappings.
+//
+// Records are sent in background as batches, only the documents which failed to be indexed are retried.
+// Sync waits for the pending records to be sent. ElasticHandler is safe for concurrent use.
+type ElasticHandler struct {
+	batcher *batcher
+	url     string
+	opts    ElasticOpts
+	action  []byte
+
+	// buffers are used by the batcher goroutine only
+	buf  bytes.Buffer
+	zbuf bytes.Buffer
+}
+
+// NewElasticHandler creates a handler indexing records to the cluster, e.g. `http://localhost:9200`.
+func NewElasticHandler(clusterURL string, opts ElasticOpts) (*ElasticHandler, error) {
+	bulkURL, err := url.JoinPath(clusterURL, "_bulk")
+	if err != nil {
+		return nil, err
+	}
+	if opts.Index == "" {
+		opts.Index = defaultElasticIndex
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultElasticTimeout}
+	}
+
+	// create action is supported by both indices and data streams
+	action := &bytes.Buffer{}
+	action.WriteString(`{"create":{`)
+	appendJSONKey(action, "_index")
+	appendJSONString(action, opts.Index)
+	action.WriteString("}}\n")
+
+	h := &ElasticHandler{
+		url:    bulkURL,
+		opts:   opts,
+		action: action.Bytes(),
+	}
+	h.batcher = newBatcher(opts.Batch, opts.Retry, h.send)
+	return h, nil
+}
+
+// Handle adds the record to the batch.
+func (h *ElasticHandler) Handle(r Record) error {
+	return h.batcher.handle(r)
+}
+
+// Sync waits for the pending records to be sent and returns the last error of sending.
+func (h *ElasticHandler) Sync() error {
+	return h.batcher.flush()
+}
+
+// Close sends the pending records and stops sending.
+func (h *ElasticHandler) Close() error {
+	return h.batcher.close()
+}
+
+// Dropped returns the number of records dropped, because there were too many pending records.
+func (h *ElasticHandler) Dropped() uint64 {
+	return h.batcher.dropped.Load()
+}
+
+func (h *ElasticHandler) send(records []Record) error {
+	h.buf.Reset()
+	for i := range records {
+		h.buf.Write(h.action)
+		appendECSDocument(&h.buf, &records[i])
+		h.buf.WriteByte('\n')
+	}
+	body := h.buf.Bytes()
+	if h.opts.Gzip {
+		h.zbuf.Reset()
+		w := gzip.NewWriter(&h.zbuf)
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = h.zbuf.Bytes()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for key, values := range h.opts.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if h.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	var resp elasticBulkResponse
+	if err := doRequest(h.opts.Client, req, &resp); err != nil {
+		return err
+	}
+	return resp.err(len(records))
+}
+
+type elasticBulkResponse struct {
+	Errors bool                               `json:"errors"`
+	Items  []map[string]elasticBulkItemResult `json:"items"`
+}
+
+type elasticBulkItemResult struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// err returns partialError with the documents to retry, when some of them failed with 429 or 5xx status,
+// and permanentError, when the documents were rejected only.
+func (r *elasticBulkResponse) err(documents int) error {
+	if len(r.Items) != documents {
+		return fmt.Errorf("%w: %d items for %d documents", errElasticInvalidResponse, len(r.Items), documents)
+	}
+	if !r.Errors {
+		return nil
+	}
+	var failed []int
+	rejected, firstErr := 0, ""
+	for i, item := range r.Items {
+		for _, result := range item {
+			if result.Status >= 200 && result.Status < 300 {
+				continue
+			}
+			if firstErr == "" {
+				firstErr = strconv.Itoa(result.Status) + " " + string(result.Error)
+			}
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				failed = append(failed, i)
+			} else {
+				rejected++
+			}
+		}
+	}
+	if len(failed) == 0 && rejected == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("log: %d of %d documents failed and %d rejected, first error: %s", len(failed), documents, rejected, firstErr)
+	if len(failed) == 0 {
+		return &permanentError{err}
+	}
+	return &partialError{err: err, failed: failed, rejected: rejected}
+}
+
+// ecsFieldSets are ECS field sets, which fields are sent at the top level, e.g. `http.request.method`,
+// log and ecs field sets are written by the handler.
+var ecsFieldSets = map[string]bool{
+	"agent": true, "client": true, "cloud": true, "container": true, "data_stream": true, "destination": true,
+	"device": true, "dns": true, "email": true, "error": true, "event": true, "faas": true, "file": true,
+	"group": true, "host": true, "http": true, "network": true, "observer": true, "orchestrator": true,
+	"organization": true, "package": true, "process": true, "registry": true, "related": true, "rule": true,
+	"server": true, "service": true, "source": true, "span": true, "threat": true, "tls": true, "trace": true,
+	"transaction": true, "url": true, "user": true, "user_agent": true, "vulnerability": true,
+}
+
+// appendECSDocument writes a record as Elastic Common Schema document.
+func appendECSDocument(buf *bytes.Buffer, r *Record) {
+	buf.WriteByte('{')
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	appendJSONKey(buf, "@timestamp")
+	appendJSONString(buf, t.UTC().Format(elasticTimeFormat))
+	appendJSONKey(buf, "log.level")
+	appendJSONString(buf, r.LevelName)
+	appendJSONKey(buf, "message")
+	appendJSONString(buf, strings.TrimRight(r.Message, "\n"))
+	if r.Name != "" {
+		appendJSONKey(buf, "log.logger")
+		appendJSONString(buf, r.Name)
+	}
+	if frame := r.Frame(); frame.File != "" {
+		appendJSONKey(buf, "log.origin.file.name")
+		appendJSONString(buf, frame.File)
+		appendJSONKey(buf, "log.origin.file.line")
+		buf.WriteString(strconv.Itoa(frame.Line))
+		appendJSONKey(buf, "log.origin.function")
+		appendJSONString(buf, frame.Function)
+	}
+
+	// ECS fields are written once, other fields are sent as labels, the last label of a key is kept
+	written := map[string]bool{}
+	var labels []Field
+	labelIndexes := map[string]int{}
+	addLabel := func(key string, value any) {
+		key = ecsLabelName(key)
+		if i, ok := labelIndexes[key]; ok {
+			labels[i].Value = value
+			return
+		}
+		labelIndexes[key] = len(labels)
+		labels = append(labels, Field{Key: key, Value: value})
+	}
+	for _, field := range labelFields(r.Labels) {
+		addLabel(field.Key, field.Value)
+	}
+	for _, field := range r.Fields {
+		key := field.Key
+		if key == "error" {
+			key = "error.message"
+		}
+		if !isECSField(key) || written[key] {
+			addLabel(field.Key, field.Value)
+			continue
+		}
+		written[key] = true
+		appendJSONKey(buf, key)
+		appendJSONValue(buf, field.Value)
+	}
+	if len(labels) > 0 {
+		appendJSONKey(buf, "labels")
+		buf.WriteByte('{')
+		for _, label := range labels {
+			appendJSONKey(buf, label.Key)
+			appendJSONString(buf, formatFieldValue(label.Value))
+		}
+		buf.WriteByte('}')
+	}
+	appendJSONKey(buf, "ecs.version")
+	appendJSONString(buf, ECSVersion)
+	buf.WriteByte('}')
+}
+
+// isECSField tells whether the key is a field of ECS field set, e.g. `http.request.method`.
+func isECSField(key string) bool {
+	set, _, ok := strings.Cut(key, ".")
+	return ok && ecsFieldSets[set]
+}
+
+// ecsLabelName replaces dots in the label key, as ECS labels are not nested.
+func ecsLabelName(key string) string {
+	return strings.ReplaceAll(key, ".", "_")
+}
@@ -0,0 +1,399 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// elasticServer records indexed documents and responds to each bulk request with the item statuses in order,
+// the item statuses are matched to the documents by the message, the last response is repeated.
+type elasticServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requests  [][]map[string]any
+	actions   []string
+	headers   []http.Header
+	responses []map[string]int
+}
+
+func newElasticServer(t *testing.T, responses ...map[string]int) *elasticServer {
+	t.Helper()
+
+	s := &elasticServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+
+		var documents []map[string]any
+		var actions []string
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			action := scanner.Text()
+			if !scanner.Scan() {
+				http.Error(w, "document expected", http.StatusBadRequest)
+				return
+			}
+			var document map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			actions = append(actions, action)
+			documents = append(documents, document)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, documents)
+		s.actions = append(s.actions, actions...)
+		s.headers = append(s.headers, r.Header)
+		var statuses map[string]int
+		if len(s.responses) > 0 {
+			statuses = s.responses[0]
+			if len(s.responses) > 1 {
+				s.responses = s.responses[1:]
+			}
+		}
+		s.mu.Unlock()
+
+		var resp strings.Builder
+		failed := false
+		for i, document := range documents {
+			if i > 0 {
+				resp.WriteByte(',')
+			}
+			status, ok := statuses[document["message"].(string)]
+			if !ok {
+				status = http.StatusCreated
+			}
+			if status >= 300 {
+				failed = true
+				fmt.Fprintf(&resp, `{"create":{"status":%d,"error":{"type":"test_exception"}}}`, status)
+				continue
+			}
+			fmt.Fprintf(&resp, `{"create":{"status":%d}}`, status)
+		}
+		fmt.Fprintf(w, `{"took":1,"errors":%t,"items":[%s]}`, failed, resp.String())
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// messages returns messages of the documents of each request.
+func (s *elasticServer) messages() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([][]string, len(s.requests))
+	for i, documents := range s.requests {
+		for _, document := range documents {
+			messages[i] = append(messages[i], document["message"].(string))
+		}
+	}
+	return messages
+}
+
+func Test_ElasticHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		gzip bool
+	}{
+		{name: "plain", gzip: false},
+		{name: "gzip", gzip: true},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newElasticServer(t)
+			h, err := NewElasticHandler(server.URL, ElasticOpts{
+				Index:  "logs-api",
+				Header: http.Header{"Authorization": []string{"ApiKey key"}},
+				Gzip:   test.gzip,
+				Batch:  BatchOpts{Interval: time.Hour},
+			})
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			logger := Named(New(CustomHandler(h)), "api")
+			WithLabels(logger, "env=prod", "k8s.pod=web-1", "worker").Errorw("failed", Err(errors.New("timeout")), "http.response.status_code", 503, "user", 1000)
+			if err := Close(logger); err != nil {
+				t.Fatalf("failed to close: %s", err)
+			}
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if len(server.requests) != 1 || len(server.requests[0]) != 1 {
+				t.Fatalf("1 request with 1 document expected, but got %v", server.requests)
+			}
+			if server.headers[0].Get("Authorization") != "ApiKey key" {
+				t.Errorf("header expected to be passed, but got %v", server.headers[0])
+			}
+			if expected := `{"create":{"_index":"logs-api"}}`; server.actions[0] != expected {
+				t.Errorf("action expected %s, but got %s", expected, server.actions[0])
+			}
+
+			document := server.requests[0][0]
+			if _, err := time.Parse(time.RFC3339Nano, document["@timestamp"].(string)); err != nil {
+				t.Errorf("RFC 3339 timestamp expected, but got %v", document["@timestamp"])
+			}
+			if file, _ := document["log.origin.file.name"].(string); !strings.HasSuffix(file, "elastic_test.go") {
+				t.Errorf("caller file expected, but got %v", document["log.origin.file.name"])
+			}
+			if line, _ := document["log.origin.file.line"].(float64); line <= 0 {
+				t.Errorf("caller line expected, but got %v", document["log.origin.file.line"])
+			}
+			if function, _ := document["log.origin.function"].(string); !strings.HasSuffix(function, "Test_ElasticHandler.func1") {
+				t.Errorf("caller function expected, but got %v", document["log.origin.function"])
+			}
+			expected := map[string]any{
+				"log.level":                 "error",
+				"message":                   "failed",
+				"log.logger":                "api",
+				"labels":                    map[string]any{"env": "prod", "k8s_pod": "web-1", "label2": "worker", "user": "1000"},
+				"error.message":             "timeout",
+				"http.response.status_code": float64(503),
+				"ecs.version":               ECSVersion,
+			}
+			for key, value := range expected {
+				if fmt.Sprint(document[key]) != fmt.Sprint(value) {
+					t.Errorf("%s expected %v, but got %v", key, value, document[key])
+				}
+			}
+		})
+	}
+}
+
+func Test_ElasticHandler_retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		responses []map[string]int
+		failing   bool
+		expected  [][]string
+	}{
+		{
+			name:      "indexed",
+			responses: nil,
+			failing:   false,
+			expected:  [][]string{{"first", "second", "third"}},
+		},
+		{
+			name: "partial-retried",
+			responses: []map[string]int{
+				{"second": http.StatusTooManyRequests, "third": http.StatusServiceUnavailable},
+				{"third": http.StatusTooManyRequests},
+				{},
+			},
+			failing:  false,
+			expected: [][]string{{"first", "second", "third"}, {"second", "third"}, {"third"}},
+		},
+		{
+			name:      "rejected",
+			responses: []map[string]int{{"second": http.StatusBadRequest}},
+			failing:   true,
+			expected:  [][]string{{"first", "second", "third"}},
+		},
+		{
+			name: "rejected-and-retried",
+			responses: []map[string]int{
+				{"first": http.StatusBadRequest, "third": http.StatusTooManyRequests},
+				{},
+			},
+			failing:  true,
+			expected: [][]string{{"first", "second", "third"}, {"third"}},
+		},
+		{
+			name:      "retries-exceeded",
+			responses: []map[string]int{{"second": http.StatusInternalServerError}},
+			failing:   true,
+			expected:  [][]string{{"first", "second", "third"}, {"second"}, {"second"}},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newElasticServer(t, test.responses...)
+			h, err := NewElasticHandler(server.URL, ElasticOpts{
+				Batch: BatchOpts{Interval: time.Hour},
+				Retry: RetryOpts{MaxRetries: 2, MinBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			defer h.Close()
+
+			logger := New(CustomHandler(h))
+			logger.Info("first")
+			logger.Info("second")
+			logger.Info("third")
+			err = h.Sync()
+			if (err != nil) != test.failing {
+				t.Errorf("failing sync expected %t, but got %v", test.failing, err)
+			}
+			if messages := server.messages(); fmt.Sprint(messages) != fmt.Sprint(test.expected) {
+				t.Errorf("requests expected %v, but got %v", test.expected, messages)
+			}
+		})
+	}
+}
+
+func Test_appendECSDocument(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "message",
+			record:   Record{LevelName: "info", Message: "started\n"},
+			expected: `{"@timestamp":"2025-03-22T14:07:50.348957Z","log.level":"info","message":"started","ecs.version":"8.11.0"}`,
+		},
+		{
+			name: "ecs-fields",
+			record: Record{
+				LevelName: "error",
+				Message:   "failed",
+				Name:      "api",
+				Fields:    []Field{Err(errors.New("timeout")), Int("http.response.status_code", 503), String("user.id", "42")},
+			},
+			expected: `{"@timestamp":"2025-03-22T14:07:50.348957Z","log.level":"error","message":"failed","log.logger":"api",` +
+				`"error.message":"timeout","http.response.status_code":503,"user.id":"42","ecs.version":"8.11.0"}`,
+		},
+		{
+			name: "labels",
+			record: Record{
+				LevelName: "info",
+				Message:   "updated",
+				Labels:    []string{"env=prod", "k8s.pod=web-1", "worker"},
+				Fields:    []Field{Int("user", 1000), String("env", "dev")},
+			},
+			expected: `{"@timestamp":"2025-03-22T14:07:50.348957Z","log.level":"info","message":"updated",` +
+				`"labels":{"env":"dev","k8s_pod":"web-1","label2":"worker","user":"1000"},"ecs.version":"8.11.0"}`,
+		},
+		{
+			name: "conflicting-fields",
+			record: Record{
+				LevelName: "info",
+				Message:   "updated",
+				Fields: []Field{
+					String("message", "y"), String("@timestamp", "now"), String("log.level", "debug"),
+					String("ecs.version", "1"), String("url.path", "/a"), String("url.path", "/b"),
+				},
+			},
+			expected: `{"@timestamp":"2025-03-22T14:07:50.348957Z","log.level":"info","message":"updated","url.path":"/a",` +
+				`"labels":{"message":"y","@timestamp":"now","log_level":"debug","ecs_version":"1","url_path":"/b"},"ecs.version":"8.11.0"}`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.record.Time = time.Date(2025, 3, 22, 15, 7, 50, 348957000, time.FixedZone("CET", 3600))
+			buf := &bytes.Buffer{}
+			appendECSDocument(buf, &test.record)
+			if buf.String() != test.expected {
+				t.Errorf("expected %s, but got %s", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func Test_elasticBulkResponse_err(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		resp      string
+		documents int
+		expected  string
+		failed    []int
+		permanent bool
+	}{
+		{
+			name:      "ok",
+			resp:      `{"errors":false,"items":[{"create":{"status":201}},{"create":{"status":201}}]}`,
+			documents: 2,
+			expected:  "",
+		},
+		{
+			name:      "mismatch",
+			resp:      `{"errors":false,"items":[{"create":{"status":201}}]}`,
+			documents: 2,
+			expected:  "log: invalid bulk response: 1 items for 2 documents",
+		},
+		{
+			name:      "failed",
+			resp:      `{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}]}`,
+			documents: 2,
+			expected:  `log: 1 of 2 documents failed and 0 rejected, first error: 429 {"type":"es_rejected_execution_exception"}`,
+			failed:    []int{1},
+		},
+		{
+			name:      "rejected",
+			resp:      `{"errors":true,"items":[{"create":{"status":400,"error":{"type":"mapper_parsing_exception"}}},{"create":{"status":201}}]}`,
+			documents: 2,
+			expected:  `log: 0 of 2 documents failed and 1 rejected, first error: 400 {"type":"mapper_parsing_exception"}`,
+			permanent: true,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resp elasticBulkResponse
+			if err := json.Unmarshal([]byte(test.resp), &resp); err != nil {
+				t.Fatalf("failed to decode response: %s", err)
+			}
+			err := resp.err(test.documents)
+			if err == nil {
+				if test.expected != "" {
+					t.Fatalf("error expected %q, but got nil", test.expected)
+				}
+				return
+			}
+			if err.Error() != test.expected {
+				t.Errorf("error expected %q, but got %q", test.expected, err)
+			}
+			var partial *partialError
+			if errors.As(err, &partial) && fmt.Sprint(partial.failed) != fmt.Sprint(test.failed) {
+				t.Errorf("failed expected %v, but got %v", test.failed, partial.failed)
+			}
+			var permanent *permanentError
+			if errors.As(err, &permanent) != test.permanent {
+				t.Errorf("permanent error expected %t, but got %v", test.permanent, err)
+			}
+		})
+	}
+}
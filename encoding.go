@@ -0,0 +1,13 @@
+package log
+
+// Encoding defines how a log message is encoded by the default Handler.
+type Encoding int
+
+const (
+	// EncodingText prints a message by Format with log.Logger header.
+	EncodingText Encoding = iota
+	// EncodingJSON prints each message as a JSON object on a separate line.
+	EncodingJSON
+	// EncodingLogfmt prints each message as a logfmt line of key=value pairs.
+	EncodingLogfmt
+)
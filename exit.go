@@ -0,0 +1,71 @@
+package log
+
+import (
+	"os"
+	"sync"
+)
+
+const defaultExitCode = 1
+
+// exiter exits the application on Fatal, it is shared by all the loggers derived from the logger.
+type exiter struct {
+	mu       sync.Mutex
+	exit     func(code int)
+	code     int
+	handlers []func()
+}
+
+func buildExiter(opts *Opts) *exiter {
+	exit := opts.ExitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	return &exiter{
+		exit: exit,
+		code: opts.ExitCode,
+	}
+}
+
+// RegisterExitHandler adds a function that is called by Fatal before the exit,
+// e.g. to close database connections. Handlers are called in the order they were registered,
+// a panic in a handler does not prevent other handlers from being called.
+//
+// Handlers are shared by all the loggers derived from the logger.
+func RegisterExitHandler(l Logger, handler func()) {
+	var e *exiter
+	switch log := l.(type) {
+	case *logger:
+		e = log.exiter
+	case *slogLogger:
+		e = log.exiter
+	}
+	if e == nil || handler == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.handlers = append(e.handlers, handler)
+}
+
+// fatal calls exit handlers, syncs the handler and exits.
+func (e *exiter) fatal(h Handler) {
+	e.mu.Lock()
+	handlers := make([]func(), len(e.handlers))
+	copy(handlers, e.handlers)
+	e.mu.Unlock()
+
+	for _, handler := range handlers {
+		runExitHandler(handler)
+	}
+	syncHandlerTimeout(h, fatalSyncTimeout)
+	e.exit(e.code)
+}
+
+func runExitHandler(handler func()) {
+	defer func() {
+		recover()
+	}()
+	handler()
+}
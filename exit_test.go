@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func Test_Fatal_exit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Opt
+		fatal    func(l Logger)
+		expected int
+	}{
+		{
+			name:     "fatal",
+			opts:     nil,
+			fatal:    func(l Logger) { l.Fatal("message") },
+			expected: defaultExitCode,
+		},
+		{
+			name:     "fatalf",
+			opts:     []Opt{ExitCode(2)},
+			fatal:    func(l Logger) { l.Fatalf("message %d", 1) },
+			expected: 2,
+		},
+		{
+			name:     "fatalw",
+			opts:     []Opt{ExitCode(3), JSON()},
+			fatal:    func(l Logger) { l.Fatalw("message", "key", "value") },
+			expected: 3,
+		},
+		{
+			name:     "async",
+			opts:     []Opt{Async(AsyncOpts{})},
+			fatal:    func(l Logger) { l.Fatal("message") },
+			expected: defaultExitCode,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			code := -1
+			var calls []string
+			opts := append(test.opts, Writer(buf), ExitFunc(func(c int) {
+				calls = append(calls, "exit")
+				code = c
+			}))
+			logger := New(opts...)
+			RegisterExitHandler(logger, func() { calls = append(calls, "first") })
+			RegisterExitHandler(WithLabels(logger, "derived"), func() { panic("handler panic") })
+			RegisterExitHandler(logger, func() { calls = append(calls, "second") })
+
+			test.fatal(logger)
+
+			if code != test.expected {
+				t.Errorf("exit code expected %d, but got %d", test.expected, code)
+			}
+			if expected := []string{"first", "second", "exit"}; !reflect.DeepEqual(calls, expected) {
+				t.Errorf("calls expected %v, but got %v", expected, calls)
+			}
+			if !bytes.Contains(buf.Bytes(), []byte("message")) {
+				t.Errorf("fatal message expected to be written before the exit, but got %q", buf.String())
+			}
+		})
+	}
+}
+
+func Test_FromSlogHandler_exit(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	code := -1
+	handled := false
+	logger := FromSlogHandler(slog.NewTextHandler(buf, nil), ExitCode(4), ExitFunc(func(c int) { code = c }))
+	RegisterExitHandler(WithFields(logger, Int("id", 1)), func() { handled = true })
+
+	logger.Fatal("message")
+
+	if code != 4 {
+		t.Errorf("exit code expected %d, but got %d", 4, code)
+	}
+	if !handled {
+		t.Errorf("exit handler expected to be called")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("message")) {
+		t.Errorf("fatal message expected to be written, but got %q", buf.String())
+	}
+}
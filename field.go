@@ -0,0 +1,173 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// badKey is used as a key of a value that has no key in key/value pairs.
+const badKey = "!BADKEY"
+
+// Field is a typed key/value pair attached to the log message.
+//
+// In a text format fields are printed inside ${labels} placeholder as `key=value`.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// String returns a text representation of the field, e.g. `user=1000`.
+func (f Field) String() string {
+	return f.Key + "=" + quoteFieldValue(formatFieldValue(f.Value))
+}
+
+// String creates a string field.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int creates an int field.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 creates an int64 field.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 creates an uint64 field.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 creates a float64 field.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool creates a bool field.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration creates a time.Duration field.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time creates a time.Time field.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err creates an error field with `error` key.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Any creates a field with any value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// WithFields adds field(s) and returns another instance of Logger,
+// so the original logger is not affected and keeps previous set of fields.
+//
+//	Example: log.WithFields(logger, log.String("user", "1000"), log.Int("attempt", 3))
+func WithFields(l Logger, fields ...Field) Logger {
+	if log, ok := l.(*slogLogger); ok {
+		return log.withFields(fields)
+	}
+	log, ok := l.(*logger)
+	if !ok {
+		return l
+	}
+	newLabels := log.labels.addFields(fields...)
+	return &logger{
+		level:      log.level,
+		format:     log.format.withLabels(newLabels.notEmpty()),
+		levelNames: log.levelNames,
+		labels:     newLabels,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
+	}
+}
+
+// fieldsFromKeyValues converts alternating key/value pairs to fields.
+//
+// Field values are taken as is, a value without a key gets `!BADKEY` key.
+func fieldsFromKeyValues(kv []any) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(kv)/2+1)
+	for i := 0; i < len(kv); i++ {
+		switch v := kv[i].(type) {
+		case Field:
+			fields = append(fields, v)
+		case string:
+			if i+1 < len(kv) {
+				fields = append(fields, Field{Key: v, Value: kv[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: v})
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: v})
+		}
+	}
+	return fields
+}
+
+func copyFields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return nil
+	}
+	newFields := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Key != "" {
+			newFields = append(newFields, field)
+		}
+	}
+	return newFields
+}
+
+func formatFieldValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+func quoteFieldValue(value string) string {
+	if needsQuoting(value) {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func needsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	return strings.ContainsFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == 0xfffd
+	})
+}
@@ -0,0 +1,160 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_fieldsFromKeyValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		kv       []any
+		expected []Field
+	}{
+		{
+			name:     "empty",
+			kv:       nil,
+			expected: nil,
+		},
+		{
+			name:     "pairs",
+			kv:       []any{"user", 1000, "name", "john"},
+			expected: []Field{{Key: "user", Value: 1000}, {Key: "name", Value: "john"}},
+		},
+		{
+			name:     "fields",
+			kv:       []any{String("user", "1000"), "attempt", 3},
+			expected: []Field{{Key: "user", Value: "1000"}, {Key: "attempt", Value: 3}},
+		},
+		{
+			name:     "missing-value",
+			kv:       []any{"user", 1000, "name"},
+			expected: []Field{{Key: "user", Value: 1000}, {Key: badKey, Value: "name"}},
+		},
+		{
+			name:     "missing-key",
+			kv:       []any{1000, "name", "john"},
+			expected: []Field{{Key: badKey, Value: 1000}, {Key: "name", Value: "john"}},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := fieldsFromKeyValues(test.kv)
+			if len(result) != len(test.expected) {
+				t.Fatalf("expected %#v, but got %#v", test.expected, result)
+			}
+			for i := range result {
+				if result[i] != test.expected[i] {
+					t.Errorf("expected %#v, but got %#v", test.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_Field_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		field    Field
+		expected string
+	}{
+		{
+			name:     "string",
+			field:    String("user", "1000"),
+			expected: "user=1000",
+		},
+		{
+			name:     "string-with-spaces",
+			field:    String("name", "john doe"),
+			expected: `name="john doe"`,
+		},
+		{
+			name:     "empty-string",
+			field:    String("name", ""),
+			expected: `name=""`,
+		},
+		{
+			name:     "int",
+			field:    Int("attempt", 3),
+			expected: "attempt=3",
+		},
+		{
+			name:     "bool",
+			field:    Bool("ok", true),
+			expected: "ok=true",
+		},
+		{
+			name:     "duration",
+			field:    Duration("elapsed", 1500*time.Millisecond),
+			expected: "elapsed=1.5s",
+		},
+		{
+			name:     "error",
+			field:    Err(errors.New("not found")),
+			expected: `error="not found"`,
+		},
+		{
+			name:     "nil",
+			field:    Any("value", nil),
+			expected: "value=<nil>",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := test.field.String()
+			if result != test.expected {
+				t.Errorf("expected %q, but got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func Test_labelsWithFields(t *testing.T) {
+	t.Parallel()
+
+	labels := buildLabels("(%[1]s)", []string{"worker-1"}, " ")
+	newLabels := labels.addFields(String("user", "1000"), Int("attempt", 3))
+	if labels.formatted != "(worker-1)" {
+		t.Fatalf("original labels formatted was updated during addFields(), but expected to stay unmodified")
+	}
+	if newLabels.formatted != "(worker-1 user=1000 attempt=3)" {
+		t.Fatalf("new labels formatted expected %q, but got %q", "(worker-1 user=1000 attempt=3)", newLabels.formatted)
+	}
+	separatedLabels := newLabels.setSeparator(", ")
+	if separatedLabels.formatted != "(worker-1, user=1000, attempt=3)" {
+		t.Fatalf("new labels formatted expected %q, but got %q", "(worker-1, user=1000, attempt=3)", separatedLabels.formatted)
+	}
+	if len(newLabels.addFields(Field{}).fields) != 2 {
+		t.Fatalf("fields without keys are expected to be skipped")
+	}
+	if clearLabels := newLabels.clear(); clearLabels.formatted != "" || clearLabels.fields != nil {
+		t.Fatalf("fields are expected to be removed during clear()")
+	}
+}
+
+func Test_WithFields(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	logger := New(Writer(buf), Flags(0))
+	WithFields(logger, String("user", "1000")).Infow("updated", "attempt", 3)
+	logger.Infow("created", Int("id", 1))
+	logger.Info("no fields")
+
+	expected := "[info] user=1000 attempt=3 updated\n[info] id=1 created\n[info] no fields\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+}
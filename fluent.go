@@ -0,0 +1,224 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const defaultFluentTimeout = 5 * time.Second
+
+// FluentOpts configures a FluentHandler, zero values are replaced by defaults.
+type FluentOpts struct {
+	// Tag of the events.
+	//
+	//	Default: the executable name
+	Tag string
+	// Ack requires the server to acknowledge each batch by `chunk` option,
+	// the batch is resent once, when it is not acknowledged.
+	Ack bool
+	// Timeout limits the time of connecting, writing a batch and waiting for the acknowledgement.
+	//
+	//	Default: 5s
+	Timeout time.Duration
+	// Batch configures batching of the events.
+	Batch BatchOpts
+}
+
+// FluentHandler is a Handler that sends records to fluentd or fluent-bit by the Fluent Forward protocol.
+//
+// Records are sent in background as batches in PackedForward mode, each record is an event with
+// msg, level, logger and caller keys, labels and fields. Sync waits for the pending records to be sent.
+//
+// The connection is reestablished, when sending fails, the batch is resent once.
+// FluentHandler is safe for concurrent use.
+type FluentHandler struct {
+	batcher *batcher
+	network string
+	addr    string
+	opts    FluentOpts
+
+	// conn and buffers are used by the batcher goroutine only
+	conn    net.Conn
+	reader  *bufio.Reader
+	buf     bytes.Buffer
+	entries bytes.Buffer
+}
+
+// DialFluent connects to the server, the network is "tcp" or "unix".
+func DialFluent(network, addr string, opts FluentOpts) (*FluentHandler, error) {
+	if opts.Tag == "" {
+		opts.Tag = filepath.Base(os.Args[0])
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultFluentTimeout
+	}
+
+	h := &FluentHandler{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+	}
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	// send resends the batch itself, so the batcher does not retry it
+	h.batcher = newBatcher(opts.Batch, RetryOpts{MaxRetries: -1}, h.send)
+	return h, nil
+}
+
+// Handle adds the record to the batch.
+func (h *FluentHandler) Handle(r Record) error {
+	return h.batcher.handle(r)
+}
+
+// Sync waits for the pending records to be sent and returns the last error of sending.
+func (h *FluentHandler) Sync() error {
+	return h.batcher.flush()
+}
+
+// Close sends the pending records and closes the connection.
+func (h *FluentHandler) Close() error {
+	err := h.batcher.close()
+	if errors.Is(err, ErrClosed) {
+		return err
+	}
+	if h.conn != nil {
+		err = errors.Join(err, h.conn.Close())
+		h.conn = nil
+	}
+	return err
+}
+
+// Dropped returns the number of records dropped, because there were too many pending records.
+func (h *FluentHandler) Dropped() uint64 {
+	return h.batcher.dropped.Load()
+}
+
+func (h *FluentHandler) connect() error {
+	conn, err := net.DialTimeout(h.network, h.addr, h.opts.Timeout)
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+	h.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// send writes the records as PackedForward message, the connection is reestablished once, when it fails.
+func (h *FluentHandler) send(records []Record) error {
+	chunk := ""
+	if h.opts.Ack {
+		chunk = newFluentChunkID()
+	}
+	h.encode(records, chunk)
+
+	if h.conn != nil {
+		if err := h.write(chunk); err == nil {
+			return nil
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	if err := h.connect(); err != nil {
+		return err
+	}
+	return h.write(chunk)
+}
+
+func (h *FluentHandler) write(chunk string) error {
+	if err := h.conn.SetDeadline(time.Now().Add(h.opts.Timeout)); err != nil {
+		return err
+	}
+	if _, err := h.conn.Write(h.buf.Bytes()); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	resp, err := decodeMsgpack(h.reader)
+	if err != nil {
+		return err
+	}
+	if m, ok := resp.(map[string]any); !ok || m["ack"] != chunk {
+		return fmt.Errorf("log: fluent chunk %s is not acknowledged: %v", chunk, resp)
+	}
+	return nil
+}
+
+// encode writes the records as [tag, entries, option], entries are concatenated [time, record] arrays.
+func (h *FluentHandler) encode(records []Record, chunk string) {
+	h.entries.Reset()
+	for i := range records {
+		appendFluentEntry(&h.entries, &records[i])
+	}
+
+	h.buf.Reset()
+	appendMsgpackArrayHeader(&h.buf, 3)
+	appendMsgpackString(&h.buf, h.opts.Tag)
+	appendMsgpackBin(&h.buf, h.entries.Bytes())
+	if chunk == "" {
+		appendMsgpackMapHeader(&h.buf, 1)
+	} else {
+		appendMsgpackMapHeader(&h.buf, 2)
+		appendMsgpackString(&h.buf, "chunk")
+		appendMsgpackString(&h.buf, chunk)
+	}
+	appendMsgpackString(&h.buf, "size")
+	appendMsgpackInt(&h.buf, int64(len(records)))
+}
+
+func appendFluentEntry(buf *bytes.Buffer, r *Record) {
+	labels := labelFields(r.Labels)
+	frame := r.Frame()
+	size := 2 + len(labels) + len(r.Fields)
+	if r.Name != "" {
+		size++
+	}
+	if frame.File != "" {
+		size++
+	}
+
+	appendMsgpackArrayHeader(buf, 2)
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	appendMsgpackEventTime(buf, t)
+	appendMsgpackMapHeader(buf, size)
+	appendMsgpackString(buf, JSONMessageKey)
+	appendMsgpackString(buf, r.Message)
+	appendMsgpackString(buf, JSONLevelKey)
+	appendMsgpackString(buf, r.LevelName)
+	if r.Name != "" {
+		appendMsgpackString(buf, JSONNameKey)
+		appendMsgpackString(buf, r.Name)
+	}
+	if frame.File != "" {
+		appendMsgpackString(buf, JSONCallerKey)
+		appendMsgpackString(buf, frame.File+":"+strconv.Itoa(frame.Line))
+	}
+	for _, field := range labels {
+		appendMsgpackString(buf, field.Key)
+		appendMsgpackValue(buf, field.Value)
+	}
+	for _, field := range r.Fields {
+		appendMsgpackString(buf, field.Key)
+		appendMsgpackValue(buf, field.Value)
+	}
+}
+
+func newFluentChunkID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return base64.StdEncoding.EncodeToString(id[:])
+}
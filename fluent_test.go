@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// fluentServer decodes PackedForward messages and optionally acknowledges them.
+type fluentServer struct {
+	listener net.Listener
+	messages chan []any
+	ack      func(chunk string) string
+}
+
+func newFluentServer(t *testing.T, ack func(chunk string) string) *fluentServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	s := &fluentServer{listener: listener, messages: make(chan []any, 10), ack: ack}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fluentServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				value, err := decodeMsgpack(r)
+				if err != nil {
+					return
+				}
+				msg, _ := value.([]any)
+				s.messages <- msg
+				if s.ack == nil || len(msg) != 3 {
+					continue
+				}
+				chunk, _ := msg[2].(map[string]any)["chunk"].(string)
+				buf := &bytes.Buffer{}
+				appendMsgpackMapHeader(buf, 1)
+				appendMsgpackString(buf, "ack")
+				appendMsgpackString(buf, s.ack(chunk))
+				conn.Write(buf.Bytes())
+			}
+		}()
+	}
+}
+
+func (s *fluentServer) next(t *testing.T) (string, []map[string]any, map[string]any) {
+	t.Helper()
+
+	var msg []any
+	select {
+	case msg = <-s.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("message expected")
+	}
+	if len(msg) != 3 {
+		t.Fatalf("message of 3 elements expected, but got %#v", msg)
+	}
+	tag, _ := msg[0].(string)
+	entries, _ := msg[1].([]byte)
+	option, _ := msg[2].(map[string]any)
+
+	var records []map[string]any
+	r := bufio.NewReader(bytes.NewReader(entries))
+	for {
+		entry, err := decodeMsgpack(r)
+		if err != nil {
+			break
+		}
+		pair, _ := entry.([]any)
+		if len(pair) != 2 {
+			t.Fatalf("entry of 2 elements expected, but got %#v", entry)
+		}
+		if ext, ok := pair[0].(msgpackExt); !ok || ext.Type != 0 || len(ext.Data) != 8 {
+			t.Errorf("event time expected, but got %#v", pair[0])
+		}
+		record, _ := pair[1].(map[string]any)
+		records = append(records, record)
+	}
+	return tag, records, option
+}
+
+func Test_FluentHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newFluentServer(t, nil)
+	h, err := DialFluent("tcp", server.listener.Addr().String(), FluentOpts{Tag: "app.logs", Batch: BatchOpts{Interval: time.Hour}})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	logger := WithLabels(Named(New(CustomHandler(h)), "db"), "user=1000")
+	logger.Info("first")
+	logger.Warnw("second", "id", 7)
+	if err := Sync(logger); err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+
+	tag, records, option := server.next(t)
+	if tag != "app.logs" {
+		t.Errorf("tag expected %q, but got %q", "app.logs", tag)
+	}
+	if option["size"] != int64(2) || option["chunk"] != nil {
+		t.Errorf("option expected size 2 without chunk, but got %v", option)
+	}
+	if len(records) != 2 {
+		t.Fatalf("2 records expected, but got %v", records)
+	}
+	expected := []map[string]any{
+		{"msg": "first", "level": "info", "logger": "db", "user": "1000"},
+		{"msg": "second", "level": "warn", "logger": "db", "user": "1000", "id": int64(7)},
+	}
+	for i, record := range records {
+		for key, value := range expected[i] {
+			if record[key] != value {
+				t.Errorf("record %d key %s expected %#v, but got %#v", i, key, value, record[key])
+			}
+		}
+		if record["caller"] == nil {
+			t.Errorf("record %d caller expected", i)
+		}
+	}
+
+	if err := Close(logger); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+}
+
+func Test_FluentHandler_ack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ack     func(chunk string) string
+		failing bool
+	}{
+		{
+			name:    "acknowledged",
+			ack:     func(chunk string) string { return chunk },
+			failing: false,
+		},
+		{
+			name:    "not-acknowledged",
+			ack:     func(chunk string) string { return "other" },
+			failing: true,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newFluentServer(t, test.ack)
+			h, err := DialFluent("tcp", server.listener.Addr().String(), FluentOpts{Ack: true, Timeout: time.Second})
+			if err != nil {
+				t.Fatalf("failed to dial: %s", err)
+			}
+			defer h.Close()
+
+			New(CustomHandler(h)).Info("message")
+			if err := h.Sync(); (err != nil) != test.failing {
+				t.Errorf("failing sync expected %t, but got %v", test.failing, err)
+			}
+			_, records, option := server.next(t)
+			if chunk, _ := option["chunk"].(string); chunk == "" || len(records) != 1 {
+				t.Errorf("chunk option and 1 record expected, but got %v and %v", option, records)
+			}
+			if test.failing {
+				// the batch is resent once through a new connection
+				if _, records, _ := server.next(t); len(records) != 1 {
+					t.Errorf("resent record expected, but got %v", records)
+				}
+			}
+		})
+	}
+}
@@ -13,6 +13,8 @@ const (
 	labelsFormat       = "%[2]s"
 	MessagePlaceholder = "${msg}"
 	messageFormat      = "%[3]s"
+	NamePlaceholder    = "${name}"
+	nameFormat         = "%[4]s"
 
 	newLine = "\n"
 )
@@ -21,13 +23,21 @@ type format struct {
 	original  string
 	value     string
 	hasLabels bool
+	hasName   bool
 }
 
 func (f format) withLabels(hasLabels bool) format {
 	if f.hasLabels == hasLabels {
 		return f
 	}
-	return buildFormat(f.original, hasLabels)
+	return buildNamedFormat(f.original, hasLabels, f.hasName)
+}
+
+func (f format) withName(hasName bool) format {
+	if f.hasName == hasName {
+		return f
+	}
+	return buildNamedFormat(f.original, f.hasLabels, hasName)
 }
 
 func (f format) clearLabels() format {
@@ -44,28 +54,45 @@ func (f format) clearLabels() format {
 //
 //	`${msg}`: is a logger message;
 //
+//	`${name}`: is a logger name, see Named, it is replaced by empty string for loggers without a name;
+//
 //	New format: `<worker-1> [${level}] ${labels} ${msg}`
 //	Example: `<worker-1> [debug] userId:1000 successfully updated`
 func WithFormat(l Logger, newFormat string) Logger {
 	log, ok := l.(*logger)
 	if !ok {
-		return log
+		return l
 	}
 	return &logger{
 		level:      log.level,
-		format:     buildFormat(newFormat, log.labels.notEmpty()),
+		format:     buildNamedFormat(newFormat, log.labels.notEmpty(), log.name != ""),
 		levelNames: log.levelNames,
 		labels:     log.labels,
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
 
 func buildFormat(newFormat string, hasLabels bool) format {
+	return buildNamedFormat(newFormat, hasLabels, false)
+}
+
+func buildNamedFormat(newFormat string, hasLabels bool, hasName bool) format {
 	original := newFormat
 	newFormat = escapeFormats(newFormat)
 
 	newFormat = strings.ReplaceAll(newFormat, LevelPlaceholder, levelFormat)
 
+	if hasName {
+		newFormat = strings.ReplaceAll(newFormat, NamePlaceholder, nameFormat)
+	} else {
+		newFormat = strings.ReplaceAll(newFormat, " ${name} ", " ")
+		newFormat = strings.ReplaceAll(newFormat, NamePlaceholder, "")
+	}
+
 	if hasLabels {
 		newFormat = strings.ReplaceAll(newFormat, LabelsPlaceholder, labelsFormat)
 	} else {
@@ -94,6 +121,7 @@ func buildFormat(newFormat string, hasLabels bool) format {
 		original:  original,
 		value:     newFormat,
 		hasLabels: hasLabels,
+		hasName:   hasName,
 	}
 }
 
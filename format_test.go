@@ -122,3 +122,48 @@ func Test_format(t *testing.T) {
 		t.Fatalf("original format was updated after withLabels(true)")
 	}
 }
+
+func Test_buildNamedFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		format    string
+		hasLabels bool
+		hasName   bool
+		expected  string
+	}{
+		{
+			name:     "with-name",
+			format:   "[${level}] ${name} ${labels} ${msg}",
+			hasName:  true,
+			expected: "[%[1]s] %[4]s %[3]s\n",
+		},
+		{
+			name:      "no-name",
+			format:    "[${level}] ${name} ${labels} ${msg}",
+			hasLabels: true,
+			expected:  "[%[1]s] %[2]s %[3]s\n",
+		},
+		{
+			name:     "no-name-without-spaces",
+			format:   "${name}: ${msg}",
+			hasName:  false,
+			expected: ": %[3]s\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			format := buildNamedFormat(test.format, test.hasLabels, test.hasName)
+			if format.value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, format.value)
+			}
+			if format.withLabels(!test.hasLabels).withLabels(test.hasLabels).value != test.expected {
+				t.Errorf("name placeholder is expected to be kept after withLabels()")
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gelfVersion = "1.1"
+
+	// gelfChunkMagic starts each chunk of a chunked message.
+	gelfChunkMagic       = "\x1e\x0f"
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+	defaultGELFChunkSize = 1420
+	defaultGELFTimeout   = 5 * time.Second
+)
+
+// ErrGELFTooLarge is returned when a message does not fit into 128 chunks.
+var ErrGELFTooLarge = errors.New("log: GELF message is too large")
+
+// GELFCompression is a compression of GELF messages sent over UDP.
+type GELFCompression int
+
+const (
+	GELFGzip GELFCompression = iota
+	GELFZlib
+	GELFNoCompression
+)
+
+// GELFOpts configures a GELFHandler, zero values are replaced by defaults.
+type GELFOpts struct {
+	// Host of the messages.
+	//
+	//	Default: os.Hostname()
+	Host string
+	// Compression of messages sent over UDP, messages are not compressed over TCP.
+	//
+	//	Default: GELFGzip
+	Compression GELFCompression
+	// ChunkSize is the maximum size of UDP datagrams, larger messages are chunked.
+	//
+	//	Default: 1420
+	ChunkSize int
+	// Timeout limits the time of connecting and writing a message.
+	//
+	//	Default: 5s
+	Timeout time.Duration
+}
+
+// GELFHandler is a Handler that sends records to Graylog as GELF 1.1 messages:
+//
+//	{"version":"1.1","host":"host","short_message":"failed","timestamp":1742656070.348957,"level":3,"_user":"1000"}
+//
+// The first line of the message is sent as short_message, multi-line messages are sent as full_message as well.
+// The level is the syslog severity of the record level, labels and fields are sent as additional fields,
+// the logger name and the caller are sent as _logger, _file and _line fields.
+//
+// GELFHandler is safe for concurrent use.
+type GELFHandler struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	zbuf    bytes.Buffer
+	network string
+	addr    string
+	opts    GELFOpts
+	conn    net.Conn
+	closed  bool
+}
+
+// DialGELF connects to Graylog, the network is "udp" or "tcp".
+//
+// Over UDP messages are compressed and chunked, over TCP they are not compressed and delimited by a null byte.
+// The TCP connection is reestablished, when sending fails.
+func DialGELF(network, addr string, opts GELFOpts) (*GELFHandler, error) {
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+	if opts.ChunkSize <= gelfChunkHeaderSize {
+		opts.ChunkSize = defaultGELFChunkSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultGELFTimeout
+	}
+
+	h := &GELFHandler{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+	}
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Handle sends the record, the connection is reestablished once, when sending fails.
+func (h *GELFHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+	h.buf.Reset()
+	encodeGELF(&h.buf, &r, h.opts.Host)
+
+	if h.conn != nil {
+		err := h.write()
+		if err == nil || errors.Is(err, ErrGELFTooLarge) {
+			return err
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	if err := h.connect(); err != nil {
+		return err
+	}
+	return h.write()
+}
+
+// Close closes the connection.
+func (h *GELFHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+func (h *GELFHandler) connect() error {
+	conn, err := net.DialTimeout(h.network, h.addr, h.opts.Timeout)
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+	return nil
+}
+
+func (h *GELFHandler) write() error {
+	if err := h.conn.SetWriteDeadline(time.Now().Add(h.opts.Timeout)); err != nil {
+		return err
+	}
+	if !h.datagram() {
+		// the buffer is kept unchanged, so the message is written again after reconnecting
+		_, err := h.conn.Write(append(h.buf.Bytes(), 0))
+		return err
+	}
+
+	msg, err := h.compress()
+	if err != nil {
+		return err
+	}
+	if len(msg) <= h.opts.ChunkSize {
+		_, err := h.conn.Write(msg)
+		return err
+	}
+	return h.writeChunks(msg)
+}
+
+// datagram tells whether messages are sent over UDP, so they should be compressed and chunked.
+func (h *GELFHandler) datagram() bool {
+	return strings.HasPrefix(h.network, "udp")
+}
+
+func (h *GELFHandler) compress() ([]byte, error) {
+	var w io.WriteCloser
+	h.zbuf.Reset()
+	switch h.opts.Compression {
+	case GELFNoCompression:
+		return h.buf.Bytes(), nil
+	case GELFZlib:
+		w = zlib.NewWriter(&h.zbuf)
+	default:
+		w = gzip.NewWriter(&h.zbuf)
+	}
+	if _, err := w.Write(h.buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return h.zbuf.Bytes(), nil
+}
+
+// writeChunks sends the message in chunks, each chunk has the magic bytes, the message id,
+// the sequence number and the count of chunks followed by a part of the message.
+func (h *GELFHandler) writeChunks(msg []byte) error {
+	size := h.opts.ChunkSize - gelfChunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return ErrGELFTooLarge
+	}
+
+	chunk := make([]byte, 0, h.opts.ChunkSize)
+	chunk = append(chunk, gelfChunkMagic...)
+	chunk = binary.BigEndian.AppendUint64(chunk, rand.Uint64())
+	chunk = append(chunk, 0, byte(count))
+	for i := 0; i < count; i++ {
+		chunk = chunk[:gelfChunkHeaderSize]
+		chunk[10] = byte(i)
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		if _, err := h.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeGELF writes a record as GELF 1.1 JSON object.
+func encodeGELF(buf *bytes.Buffer, r *Record, host string) {
+	msg := strings.TrimRight(r.Message, "\n")
+	short, _, multiline := strings.Cut(msg, "\n")
+
+	buf.WriteByte('{')
+	appendJSONKey(buf, "version")
+	appendJSONString(buf, gelfVersion)
+	appendJSONKey(buf, "host")
+	appendJSONString(buf, host)
+	appendJSONKey(buf, "short_message")
+	appendJSONString(buf, short)
+	if multiline {
+		appendJSONKey(buf, "full_message")
+		appendJSONString(buf, msg)
+	}
+	if !r.Time.IsZero() {
+		appendJSONKey(buf, "timestamp")
+		buf.WriteString(strconv.FormatInt(r.Time.Unix(), 10))
+		buf.WriteByte('.')
+		buf.Write(appendInt(nil, r.Time.Nanosecond()/1e3, 6))
+	}
+	appendJSONKey(buf, "level")
+	buf.WriteString(strconv.Itoa(syslogSeverity(r.Level)))
+	if r.Name != "" {
+		appendJSONKey(buf, "_logger")
+		appendJSONString(buf, r.Name)
+	}
+	if frame := r.Frame(); frame.File != "" {
+		appendJSONKey(buf, "_file")
+		appendJSONString(buf, frame.File)
+		appendJSONKey(buf, "_line")
+		buf.WriteString(strconv.Itoa(frame.Line))
+	}
+	for _, field := range labelFields(r.Labels) {
+		appendJSONKey(buf, gelfFieldName(field.Key))
+		appendGELFValue(buf, field.Value)
+	}
+	for _, field := range r.Fields {
+		appendJSONKey(buf, gelfFieldName(field.Key))
+		appendGELFValue(buf, field.Value)
+	}
+	buf.WriteByte('}')
+}
+
+// gelfFieldName prefixes the key by an underscore and replaces characters which are not allowed with underscores,
+// `id` key is reserved, so it is sent as `__id`.
+func gelfFieldName(key string) string {
+	if key == "id" {
+		return "__id"
+	}
+	return "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// appendGELFValue writes numbers as they are, other values as strings, as GELF allows only strings and numbers.
+func appendGELFValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		// NaN and infinity are written as strings
+		appendJSONValue(buf, v)
+	default:
+		appendJSONString(buf, formatFieldValue(value))
+	}
+}
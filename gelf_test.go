@@ -0,0 +1,252 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_encodeGELF(t *testing.T) {
+	t.Parallel()
+
+	tm := time.Date(2025, 3, 22, 15, 7, 50, 348957000, time.UTC)
+	tests := []struct {
+		name     string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "message",
+			record:   Record{Time: tm, Level: LevelInfo, Message: "started\n"},
+			expected: `{"version":"1.1","host":"host","short_message":"started","timestamp":1742656070.348957,"level":6}`,
+		},
+		{
+			name:     "multi-line",
+			record:   Record{Level: LevelError, Message: "failed\nstack"},
+			expected: `{"version":"1.1","host":"host","short_message":"failed","full_message":"failed\nstack","level":3}`,
+		},
+		{
+			name: "fields",
+			record: Record{
+				Level: LevelWarn, Message: "slow", Name: "db",
+				Labels: []string{"user=1000", "worker"},
+				Fields: []Field{Int("id", 7), Float64("ms", 1.5), Float64("nan", math.NaN()), Bool("ok", true), String("my key", "v")},
+			},
+			expected: `{"version":"1.1","host":"host","short_message":"slow","level":4,"_logger":"db",` +
+				`"_user":"1000","_label1":"worker","__id":7,"_ms":1.5,"_nan":"NaN","_ok":"true","_my_key":"v"}`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			encodeGELF(buf, &test.record, "host")
+			if buf.String() != test.expected {
+				t.Errorf("message expected %s, but got %s", test.expected, buf.String())
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("valid JSON expected, but got %s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_DialGELF_udp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		compression GELFCompression
+		chunkSize   int
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:        "gzip",
+			compression: GELFGzip,
+			decompress:  func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:        "zlib",
+			compression: GELFZlib,
+			decompress:  func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		},
+		{
+			name:        "no-compression",
+			compression: GELFNoCompression,
+			decompress:  func(r io.Reader) (io.Reader, error) { return r, nil },
+		},
+		{
+			name:        "chunked",
+			compression: GELFNoCompression,
+			chunkSize:   100,
+			decompress:  func(r io.Reader) (io.Reader, error) { return r, nil },
+		},
+		{
+			name:        "chunked-gzip",
+			compression: GELFGzip,
+			chunkSize:   50,
+			decompress:  func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to listen: %s", err)
+			}
+			defer conn.Close()
+
+			h, err := DialGELF("udp", conn.LocalAddr().String(), GELFOpts{Host: "host", Compression: test.compression, ChunkSize: test.chunkSize})
+			if err != nil {
+				t.Fatalf("failed to dial: %s", err)
+			}
+			defer h.Close()
+			WithLabels(New(CustomHandler(h)), "user=1000").Error("failed to update the user")
+
+			r, err := test.decompress(bytes.NewReader(readGELFMessage(t, conn)))
+			if err != nil {
+				t.Fatalf("failed to decompress: %s", err)
+			}
+			var msg map[string]any
+			if err := json.NewDecoder(r).Decode(&msg); err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if msg["short_message"] != "failed to update the user" || msg["level"] != 3.0 || msg["_user"] != "1000" {
+				t.Errorf("unexpected message %v", msg)
+			}
+		})
+	}
+}
+
+func Test_GELFHandler_tooLarge(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer conn.Close()
+
+	h, err := DialGELF("udp", conn.LocalAddr().String(), GELFOpts{Compression: GELFNoCompression, ChunkSize: 20})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+	if err := h.Handle(Record{Message: strings.Repeat("a", 8*gelfMaxChunks)}); !errors.Is(err, ErrGELFTooLarge) {
+		t.Errorf("error expected %v, but got %v", ErrGELFTooLarge, err)
+	}
+}
+
+func Test_DialGELF_tcp(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer listener.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	h, err := DialGELF("tcp", listener.Addr().String(), GELFOpts{Host: "host"})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+	logger := New(CustomHandler(h))
+
+	first := <-conns
+	defer first.Close()
+	firstReader := bufio.NewReader(first)
+	logger.Info("first")
+	logger.Info("second")
+	for _, expected := range []string{"first", "second"} {
+		readGELFFrame(t, firstReader, expected)
+	}
+
+	// the broken connection is replaced by a new one, the message is written with a single delimiter
+	h.conn = brokenConn{h.conn}
+	logger.Info("reconnected")
+	second := <-conns
+	defer second.Close()
+	secondReader := bufio.NewReader(second)
+	readGELFFrame(t, secondReader, "reconnected")
+	if err := h.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if rest, err := io.ReadAll(secondReader); err != nil || len(rest) > 0 {
+		t.Errorf("no more data expected, but got %q: %v", rest, err)
+	}
+}
+
+// brokenConn fails writes, while the deadlines can be set.
+type brokenConn struct {
+	net.Conn
+}
+
+func (c brokenConn) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+// readGELFFrame reads a null delimited message and checks its short message.
+func readGELFFrame(t *testing.T, r *bufio.Reader, expected string) {
+	t.Helper()
+
+	msg, err := r.ReadBytes(0)
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if prefix := `{"version":"1.1","host":"host","short_message":"` + expected + `"`; !bytes.HasPrefix(msg, []byte(prefix)) {
+		t.Errorf("message expected to start with %s, but got %s", prefix, msg)
+	}
+}
+
+// readGELFMessage reads a datagram, chunked messages are reassembled.
+func readGELFMessage(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+
+	var chunks [][]byte
+	for received := 0; ; {
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatalf("failed to set deadline: %s", err)
+		}
+		buf := make([]byte, 2048)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("failed to read: %s", err)
+		}
+		if !bytes.HasPrefix(buf, []byte(gelfChunkMagic)) {
+			return buf[:n]
+		}
+		seq, count := int(buf[10]), int(buf[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[seq] = buf[gelfChunkHeaderSize:n]
+		if received++; received == count {
+			return bytes.Join(chunks, nil)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Handler handles records of a Logger, e.g. encodes and writes them.
+//
+// Records are passed to Handle only when their level is allowed by the logger,
+// Handle is called concurrently, so the handler must be safe for concurrent use.
+type Handler interface {
+	Handle(Record) error
+}
+
+// StdHandler creates a Handler that prints records by the logger format through log.Logger.
+//
+// log.Logger flags and prefix are printed as a header, the same way log.Logger does.
+// When the flags include the file or the line, they are taken from the record,
+// so the handler builds the header itself and writes the line to log.Logger writer.
+func StdHandler(l *log.Logger) Handler {
+	return &stdHandler{logger: l, out: log.New(io.Discard, "", 0)}
+}
+
+type stdHandler struct {
+	mu     sync.Mutex
+	buf    []byte
+	logger *log.Logger
+	// out writes the lines with the header built by the handler
+	out *log.Logger
+}
+
+func (h *stdHandler) Handle(r Record) error {
+	text := r.Text()
+	flags, prefix := h.logger.Flags(), h.logger.Prefix()
+	if flags&(log.Lshortfile|log.Llongfile) == 0 {
+		return h.logger.Output(0, text)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.buf = h.buf[:0]
+	if flags&log.Lmsgprefix == 0 {
+		h.buf = append(h.buf, prefix...)
+	}
+	h.buf = appendHeader(h.buf, flags, &r)
+	if flags&log.Lmsgprefix != 0 {
+		h.buf = append(h.buf, prefix...)
+	}
+	h.buf = append(h.buf, text...)
+	h.out.SetOutput(h.logger.Writer())
+	return h.out.Output(0, string(h.buf))
+}
+
+// Sync commits the data of the writer, e.g. *os.File.
+func (h *stdHandler) Sync() error {
+	return syncWriter(h.logger.Writer())
+}
+
+// Close closes the writer, standard output and error are not closed.
+func (h *stdHandler) Close() error {
+	return closeWriter(h.logger.Writer())
+}
+
+// appendHeader appends the header the same way log.Logger does:
+//
+//	2009/01/23 01:23:23.123123 /a/b/c/d.go:23:
+func appendHeader(buf []byte, flags int, r *Record) []byte {
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		t := r.Time
+		if flags&log.LUTC != 0 {
+			t = t.UTC()
+		}
+		if flags&log.Ldate != 0 {
+			year, month, day := t.Date()
+			buf = appendInt(buf, year, 4)
+			buf = append(buf, '/')
+			buf = appendInt(buf, int(month), 2)
+			buf = append(buf, '/')
+			buf = appendInt(buf, day, 2)
+			buf = append(buf, ' ')
+		}
+		if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+			hour, minute, second := t.Clock()
+			buf = appendInt(buf, hour, 2)
+			buf = append(buf, ':')
+			buf = appendInt(buf, minute, 2)
+			buf = append(buf, ':')
+			buf = appendInt(buf, second, 2)
+			if flags&log.Lmicroseconds != 0 {
+				buf = append(buf, '.')
+				buf = appendInt(buf, t.Nanosecond()/1e3, 6)
+			}
+			buf = append(buf, ' ')
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		file, line := "???", 0
+		if frame := r.Frame(); frame.File != "" {
+			file, line = frame.File, frame.Line
+		}
+		if flags&log.Lshortfile != 0 {
+			file = shortFile(file)
+		}
+		buf = append(buf, file...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(line), 10)
+		buf = append(buf, ": "...)
+	}
+	return buf
+}
+
+// appendInt appends a zero-padded decimal with the fixed width.
+func appendInt(buf []byte, i int, width int) []byte {
+	var b [20]byte
+	pos := len(b) - 1
+	for i >= 10 || width > 1 {
+		width--
+		b[pos] = byte('0' + i%10)
+		pos--
+		i /= 10
+	}
+	b[pos] = byte('0' + i)
+	return append(buf, b[pos:]...)
+}
+
+func shortFile(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// JSONHandler creates a Handler that prints each record as a JSON object on a separate line.
+//
+// Flags are not printed as a header, but the time and the caller are added according to them.
+func JSONHandler(w io.Writer, flags int) Handler {
+	return &encodingHandler{
+		writer: w,
+		flags:  flags,
+		encode: encodeJSON,
+	}
+}
+
+// LogfmtHandler creates a Handler that prints each record as a logfmt line.
+//
+// Flags are not printed as a header, but the time and the caller are added according to them.
+func LogfmtHandler(w io.Writer, flags int) Handler {
+	return &encodingHandler{
+		writer: w,
+		flags:  flags,
+		encode: encodeLogfmt,
+	}
+}
+
+// encodingHandler writes records to the writer in a particular encoding,
+// log.Logger flags are used to decide whether time and caller are written.
+type encodingHandler struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writer io.Writer
+	// out writes the lines to the writer of log.Logger passed by CustomLogger, when it is set
+	out    *log.Logger
+	flags  int
+	encode func(buf *bytes.Buffer, r *Record, flags int)
+}
+
+func (h *encodingHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.buf.Reset()
+	h.encode(&h.buf, &r, h.flags)
+	if h.out != nil {
+		return h.out.Output(0, h.buf.String())
+	}
+	_, err := h.writer.Write(h.buf.Bytes())
+	return err
+}
+
+// Sync commits the data of the writer, e.g. *os.File.
+func (h *encodingHandler) Sync() error {
+	return syncWriter(h.writer)
+}
+
+// Close closes the writer, standard output and error are not closed.
+func (h *encodingHandler) Close() error {
+	return closeWriter(h.writer)
+}
+
+func buildHandler(opts *Opts) Handler {
+	handler := buildOutputHandler(opts)
+	if opts.Async != nil {
+		return NewAsyncHandler(handler, *opts.Async)
+	}
+	return handler
+}
+
+func buildOutputHandler(opts *Opts) Handler {
+	if opts.Handler != nil {
+		return opts.Handler
+	}
+	if len(opts.Sinks) > 0 {
+		return buildTeeHandler(opts)
+	}
+	if opts.Encoding == EncodingText {
+		return StdHandler(buildLogger(opts))
+	}
+
+	var handler *encodingHandler
+	writer, flags := opts.Writer, opts.Flags
+	if opts.Logger != nil {
+		writer, flags = opts.Logger.Writer(), opts.Logger.Flags()
+	}
+	if writer == nil {
+		writer = os.Stderr
+	}
+	if opts.Encoding == EncodingLogfmt {
+		handler = LogfmtHandler(writer, flags).(*encodingHandler)
+	} else {
+		handler = JSONHandler(writer, flags).(*encodingHandler)
+	}
+	if opts.Logger != nil {
+		// writes through log.Logger are serialised by its lock
+		handler.out = log.New(writer, "", 0)
+	}
+	return handler
+}
+
+func encodeTime(t time.Time, flags int) (string, bool) {
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) == 0 {
+		return "", false
+	}
+	if flags&log.LUTC != 0 {
+		t = t.UTC()
+	}
+	if flags&log.Lmicroseconds != 0 {
+		return t.Format("2006-01-02T15:04:05.000000Z07:00"), true
+	}
+	return t.Format(time.RFC3339), true
+}
+
+func encodeCaller(r *Record, flags int) (string, bool) {
+	if r.PC == 0 || flags&(log.Lshortfile|log.Llongfile) == 0 {
+		return "", false
+	}
+	frame := r.Frame()
+	file := frame.File
+	if flags&log.Lshortfile != 0 {
+		file = shortFile(file)
+	}
+	return file + ":" + strconv.Itoa(frame.Line), true
+}
@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_appendHeader(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2009, 1, 23, 1, 23, 23, 123123000, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name     string
+		flags    int
+		expected string
+	}{
+		{
+			name:     "no-flags",
+			flags:    0,
+			expected: "",
+		},
+		{
+			name:     "std-flags",
+			flags:    log.LstdFlags,
+			expected: "2009/01/23 01:23:23 ",
+		},
+		{
+			name:     "microseconds-utc",
+			flags:    log.Ldate | log.Lmicroseconds | log.LUTC,
+			expected: "2009/01/23 00:23:23.123123 ",
+		},
+		{
+			name:     "unknown-caller",
+			flags:    log.Lshortfile,
+			expected: "???:0: ",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := string(appendHeader(nil, test.flags, &Record{Time: date}))
+			if result != test.expected {
+				t.Errorf("expected %q, but got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func Test_StdHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		flags    int
+		expected string
+	}{
+		{
+			name:     "prefix",
+			prefix:   "app: ",
+			flags:    log.Lshortfile,
+			expected: "app: handler_test.go:[info] message\n",
+		},
+		{
+			name:     "message-prefix",
+			prefix:   "app: ",
+			flags:    log.Lshortfile | log.Lmsgprefix,
+			expected: "handler_test.go:app: [info] message\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			New(CustomLogger(log.New(buf, test.prefix, test.flags))).Info("message")
+
+			// removes the line number
+			result := buf.String()
+			if start := strings.Index(result, "handler_test.go:"); start >= 0 {
+				start += len("handler_test.go:")
+				end := start + strings.Index(result[start:], ": ")
+				result = result[:start] + result[end+2:]
+			}
+			if result != test.expected {
+				t.Errorf("expected %q, but got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func Test_CustomLogger_concurrent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		flags int
+	}{
+		{name: "no-flags", flags: 0},
+		{name: "std-flags", flags: log.LstdFlags},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// the loggers write to the same buffer, so the race detector fails, when they are not serialised
+			buf := &bytes.Buffer{}
+			std := log.New(buf, "", test.flags)
+			logger := New(CustomLogger(std))
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					logger.Info("message")
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					std.Print("message")
+				}
+			}()
+			wg.Wait()
+
+			if lines := strings.Count(buf.String(), "\n"); lines != 200 {
+				t.Errorf("200 lines expected, but got %d", lines)
+			}
+		})
+	}
+}
+
+type testHandler struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (h *testHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append(h.records, r)
+	return nil
+}
+
+func Test_CustomHandler(t *testing.T) {
+	t.Parallel()
+
+	handler := &testHandler{}
+	logger := New(CustomHandler(handler), Labels("worker-1"), UpperCaseNames())
+	logger.Debug("not logged")
+	logger.Warnw("slow", "elapsed", time.Second)
+
+	if len(handler.records) != 1 {
+		t.Fatalf("expected 1 record, but got %d", len(handler.records))
+	}
+	r := handler.records[0]
+	if r.Level != LevelWarn || r.LevelName != "WARN" || r.Message != "slow" {
+		t.Errorf("unexpected record %#v", r)
+	}
+	if len(r.Labels) != 1 || r.Labels[0] != "worker-1" {
+		t.Errorf("labels expected %#v, but got %#v", []string{"worker-1"}, r.Labels)
+	}
+	if len(r.Fields) != 1 || r.Fields[0] != Duration("elapsed", time.Second) {
+		t.Errorf("fields expected %#v, but got %#v", []Field{Duration("elapsed", time.Second)}, r.Fields)
+	}
+	if r.Time.IsZero() || r.PC == 0 {
+		t.Errorf("time and caller are expected to be set")
+	}
+	if r.Text() != "[WARN] worker-1 elapsed=1s slow\n" {
+		t.Errorf("text expected %q, but got %q", "[WARN] worker-1 elapsed=1s slow\n", r.Text())
+	}
+}
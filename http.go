@@ -0,0 +1,93 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxLevelRequestSize limits the body of a request changing the level.
+const maxLevelRequestSize = 4 << 10
+
+type levelPayload struct {
+	Level string `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// LevelHandler returns http.Handler that shows and changes the level of the logger and all the loggers sharing it.
+//
+// GET responds with the current level name, e.g. {"level":"info"}.
+// PUT and POST change the level by its name, which is recognised ignoring the case.
+// The name is passed either as JSON {"level":"trace"} or as a `level` query or form value.
+//
+//	Example: curl -X PUT -d '{"level":"trace"}' -H 'Content-Type: application/json' localhost:8080/log/level
+func LevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level, levelNames, ok := loggerLevel(l)
+		if !ok {
+			writeLevelPayload(w, http.StatusNotImplemented, levelPayload{Error: "logger level can not be changed"})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			r.Body = http.MaxBytesReader(w, r.Body, maxLevelRequestSize)
+			name, err := requestLevelName(r)
+			if err != nil {
+				status := http.StatusBadRequest
+				if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				writeLevelPayload(w, status, levelPayload{Error: err.Error()})
+				return
+			}
+			newLevel, ok := parseLevelName(levelNames, name)
+			if !ok {
+				writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: fmt.Sprintf("unknown level %q", name)})
+				return
+			}
+			level.SetLevel(newLevel)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelPayload(w, http.StatusMethodNotAllowed, levelPayload{Error: "method not allowed"})
+			return
+		}
+		writeLevelPayload(w, http.StatusOK, levelPayload{Level: levelNames[level.Level()]})
+	})
+}
+
+// loggerLevel returns the level of the logger and its level names, slog loggers use the default names.
+func loggerLevel(l Logger) (*AtomicLevel, map[Level]string, bool) {
+	switch l := l.(type) {
+	case *logger:
+		return l.level, l.levelNames, true
+	case *slogLogger:
+		return l.level, copyLevelNames(nil), true
+	}
+	return nil, nil, false
+}
+
+func requestLevelName(r *http.Request) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var payload levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+		return strings.TrimSpace(payload.Level), nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(r.Form.Get("level")), nil
+}
+
+func writeLevelPayload(w http.ResponseWriter, status int, payload levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
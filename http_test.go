@@ -0,0 +1,143 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_LevelHandler(t *testing.T) {
+	t.Parallel()
+
+	level := NewAtomicLevel(LevelInfo)
+	logger := New(SharedLevel(level), UpperCaseNames(), LevelName(LevelTrace, "trc"))
+	handler := LevelHandler(WithLabels(logger, "user=1000"))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		status      int
+		expected    string
+		level       Level
+	}{
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			target:   "/",
+			status:   http.StatusOK,
+			expected: `{"level":"INFO"}`,
+			level:    LevelInfo,
+		},
+		{
+			name:        "put-json",
+			method:      http.MethodPut,
+			target:      "/",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"level":"trc"}`,
+			status:      http.StatusOK,
+			expected:    `{"level":"TRC"}`,
+			level:       LevelTrace,
+		},
+		{
+			name:        "post-form",
+			method:      http.MethodPost,
+			target:      "/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "level=debug",
+			status:      http.StatusOK,
+			expected:    `{"level":"DEBUG"}`,
+			level:       LevelDebug,
+		},
+		{
+			name:     "put-query",
+			method:   http.MethodPut,
+			target:   "/?level=WARN",
+			status:   http.StatusOK,
+			expected: `{"level":"WARN"}`,
+			level:    LevelWarn,
+		},
+		{
+			name:     "unknown-level",
+			method:   http.MethodPut,
+			target:   "/?level=verbose",
+			status:   http.StatusBadRequest,
+			expected: `{"error":"unknown level \"verbose\""}`,
+			level:    LevelWarn,
+		},
+		{
+			name:        "invalid-json",
+			method:      http.MethodPut,
+			target:      "/",
+			contentType: "application/json",
+			body:        `{"level":`,
+			status:      http.StatusBadRequest,
+			expected:    `{"error":"invalid JSON: unexpected EOF"}`,
+			level:       LevelWarn,
+		},
+		{
+			name:        "too-large",
+			method:      http.MethodPut,
+			target:      "/",
+			contentType: "application/json",
+			body:        `{"level":"` + strings.Repeat(" ", maxLevelRequestSize) + `debug"}`,
+			status:      http.StatusRequestEntityTooLarge,
+			expected:    `{"error":"invalid JSON: http: request body too large"}`,
+			level:       LevelWarn,
+		},
+		{
+			name:     "method-not-allowed",
+			method:   http.MethodDelete,
+			target:   "/",
+			status:   http.StatusMethodNotAllowed,
+			expected: `{"error":"method not allowed"}`,
+			level:    LevelWarn,
+		},
+	}
+	// the tests change the same level, so they are run sequentially
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.status {
+				t.Errorf("status expected %d, but got %d", test.status, rec.Code)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != test.expected {
+				t.Errorf("body expected %s, but got %s", test.expected, body)
+			}
+			if level.Level() != test.level {
+				t.Errorf("level expected %d, but got %d", test.level, level.Level())
+			}
+		})
+	}
+}
+
+func Test_LevelHandler_slog(t *testing.T) {
+	t.Parallel()
+
+	level := NewAtomicLevel(LevelInfo)
+	logger := FromSlogHandler(slog.NewTextHandler(io.Discard, nil), SharedLevel(level))
+	req := httptest.NewRequest(http.MethodPut, "/?level=debug", nil)
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status expected %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if expected, body := `{"level":"debug"}`, strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("body expected %s, but got %s", expected, body)
+	}
+	if level.Level() != LevelDebug {
+		t.Errorf("level expected %d, but got %d", LevelDebug, level.Level())
+	}
+}
@@ -16,45 +16,54 @@ type (
 	TraceLogger interface {
 		Trace(...any)
 		Tracef(string, ...any)
+		Tracew(string, ...any)
 	}
 
 	DebugLogger interface {
 		Debug(...any)
 		Debugf(string, ...any)
+		Debugw(string, ...any)
 	}
 
 	InfoLogger interface {
 		Info(...any)
 		Infof(string, ...any)
+		Infow(string, ...any)
 	}
 
 	NoticeLogger interface {
 		Notice(...any)
 		Noticef(string, ...any)
+		Noticew(string, ...any)
 	}
 
 	WarnLogger interface {
 		Warn(...any)
 		Warnf(string, ...any)
+		Warnw(string, ...any)
 	}
 
 	ErrorLogger interface {
 		Error(...any)
 		Errorf(string, ...any)
+		Errorw(string, ...any)
 	}
 
 	PanicLogger interface {
 		Panic(...any)
 		Panicf(string, ...any)
+		Panicw(string, ...any)
 	}
 
 	FatalLogger interface {
 		Fatal(...any)
 		Fatalf(string, ...any)
+		Fatalw(string, ...any)
 	}
 
 	LevelLogger interface {
 		Log(Level, ...any)
 		Logf(Level, string, ...any)
+		Logw(Level, string, ...any)
 	}
 )
@@ -0,0 +1,272 @@
+//go:build linux
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// JournalSocket is the socket of the journald native protocol.
+const JournalSocket = "/run/systemd/journal/socket"
+
+// journalFileDirs keep files of large entries, when memfd is not supported,
+// journald accepts unsealed files only from these directories.
+var journalFileDirs = []string{"/dev/shm", "/tmp", "/var/tmp"}
+
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 0x409
+	// F_SEAL_SEAL, F_SEAL_SHRINK, F_SEAL_GROW and F_SEAL_WRITE
+	journalFileSeals = 0x1 | 0x2 | 0x4 | 0x8
+)
+
+// journalReservedFields are written by the handler, so labels and fields with these names are prefixed.
+var journalReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"LOGGER":            true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+// JournalOpts configures a JournalHandler.
+type JournalOpts struct {
+	// Identifier is sent as SYSLOG_IDENTIFIER field.
+	//
+	//	Default: the executable name
+	Identifier string
+}
+
+// JournalHandler is a Handler that sends records to journald by the native protocol.
+//
+// Records are sent with MESSAGE, PRIORITY, SYSLOG_IDENTIFIER, CODE_FILE, CODE_LINE and CODE_FUNC fields,
+// the logger name is sent as LOGGER field, each label and field is sent as its own field with upper case name,
+// e.g. `user=1000` -> USER=1000, the names of the fields above are prefixed, e.g. `message` -> FIELD_MESSAGE.
+// PRIORITY is the syslog severity of the level.
+//
+// Entries exceeding the datagram size are written to a sealed memfd, which is passed to journald.
+// JournalHandler is safe for concurrent use.
+type JournalHandler struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	addr   *net.UnixAddr
+	conn   *net.UnixConn
+	opts   JournalOpts
+	closed bool
+}
+
+// DialJournal creates a handler sending records to the journald socket, JournalSocket is used when addr is empty.
+func DialJournal(addr string, opts JournalOpts) (*JournalHandler, error) {
+	if addr == "" {
+		addr = JournalSocket
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	if _, err := os.Stat(addr); err != nil {
+		return nil, err
+	}
+	// the socket is not connected, so entries are still delivered after journald restarts
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournalHandler{
+		addr: &net.UnixAddr{Name: addr, Net: "unixgram"},
+		conn: conn,
+		opts: opts,
+	}, nil
+}
+
+// Handle sends the record as a journal entry.
+func (h *JournalHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+	h.buf.Reset()
+	h.encode(&r)
+
+	_, _, err := h.conn.WriteMsgUnix(h.buf.Bytes(), nil, h.addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return h.sendFile()
+	}
+	return err
+}
+
+// Close closes the socket.
+func (h *JournalHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	return h.conn.Close()
+}
+
+// sendFile writes the entry to a sealed memfd or an unlinked temporary file and passes its descriptor to journald,
+// the same way sd_journal_send does for large entries.
+func (h *JournalHandler) sendFile() error {
+	file, err := createJournalMemfd()
+	memfd := err == nil
+	if !memfd {
+		file, err = createJournalTempFile()
+		if err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+
+	if _, err := file.Write(h.buf.Bytes()); err != nil {
+		return err
+	}
+	if memfd {
+		if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), fAddSeals, journalFileSeals); errno != 0 {
+			return errno
+		}
+	}
+	_, _, err = h.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), h.addr)
+	return err
+}
+
+// createJournalMemfd creates a memfd named `journal`, which allows sealing.
+func createJournalMemfd() (*os.File, error) {
+	trap, ok := sysMemfdCreate()
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+	name, err := syscall.BytePtrFromString("journal")
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return os.NewFile(fd, "journal"), nil
+}
+
+// createJournalTempFile creates an unlinked file in one of journalFileDirs.
+func createJournalTempFile() (*os.File, error) {
+	var errs []error
+	for _, dir := range journalFileDirs {
+		file, err := os.CreateTemp(dir, "journal-*")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(file.Name()); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return file, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// sysMemfdCreate returns the number of memfd_create syscall, as syscall package does not define it
+// for all the architectures.
+func sysMemfdCreate() (uintptr, bool) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return 319, true
+	case "386":
+		return 356, true
+	case "arm":
+		return 385, true
+	case "arm64", "loong64", "riscv64":
+		return 279, true
+	case "ppc64", "ppc64le":
+		return 360, true
+	case "s390x":
+		return 350, true
+	case "mips", "mipsle":
+		return 4354, true
+	case "mips64", "mips64le":
+		return 5314, true
+	}
+	return 0, false
+}
+
+func (h *JournalHandler) encode(r *Record) {
+	appendJournalField(&h.buf, "MESSAGE", strings.TrimRight(r.Message, "\n"))
+	appendJournalField(&h.buf, "PRIORITY", strconv.Itoa(syslogSeverity(r.Level)))
+	appendJournalField(&h.buf, "SYSLOG_IDENTIFIER", h.opts.Identifier)
+	if r.Name != "" {
+		appendJournalField(&h.buf, "LOGGER", r.Name)
+	}
+	if frame := r.Frame(); frame.File != "" {
+		appendJournalField(&h.buf, "CODE_FILE", frame.File)
+		appendJournalField(&h.buf, "CODE_LINE", strconv.Itoa(frame.Line))
+		appendJournalField(&h.buf, "CODE_FUNC", frame.Function)
+	}
+	for _, field := range labelFields(r.Labels) {
+		appendJournalField(&h.buf, journalFieldName(field.Key), formatFieldValue(field.Value))
+	}
+	for _, field := range r.Fields {
+		appendJournalField(&h.buf, journalFieldName(field.Key), formatFieldValue(field.Value))
+	}
+}
+
+// appendJournalField writes the field as `NAME=value\n`,
+// values with new lines are written as the name, the little endian length and the value.
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts the key to a journal field name, which consists of upper case letters,
+// digits and underscores, does not start with an underscore or a digit and is up to 64 characters long,
+// the names of the fields written by the handler are prefixed.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "FIELD_" + name
+	}
+	if journalReservedFields[name] {
+		name = "FIELD_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
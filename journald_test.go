@@ -0,0 +1,193 @@
+//go:build linux
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_journalFieldName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "user", expected: "USER"},
+		{key: "request-id", expected: "REQUEST_ID"},
+		{key: "_trusted", expected: "TRUSTED"},
+		{key: "0day", expected: "FIELD_0DAY"},
+		{key: "", expected: "FIELD_"},
+		{key: "ключ", expected: "FIELD_"},
+		{key: strings.Repeat("a", 70), expected: strings.Repeat("A", 64)},
+		{key: "message", expected: "FIELD_MESSAGE"},
+		{key: "priority", expected: "FIELD_PRIORITY"},
+		{key: "code_line", expected: "FIELD_CODE_LINE"},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.key, func(t *testing.T) {
+			t.Parallel()
+
+			if name := journalFieldName(test.key); name != test.expected {
+				t.Errorf("name expected %q, but got %q", test.expected, name)
+			}
+		})
+	}
+}
+
+func Test_JournalHandler(t *testing.T) {
+	t.Parallel()
+
+	conn, path := listenJournal(t)
+	h, err := DialJournal(path, JournalOpts{Identifier: "app"})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+
+	logger := WithLabels(Named(New(CustomHandler(h)), "db"), "user=1000", "worker")
+	logger.Warnw("multi\nline", "request-id", 7, "priority", "high")
+
+	fields := readJournalEntry(t, conn)
+	expected := map[string]string{
+		"MESSAGE":           "multi\nline",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "app",
+		"LOGGER":            "db",
+		"CODE_FILE":         "journald_test.go",
+		"USER":              "1000",
+		"LABEL1":            "worker",
+		"REQUEST_ID":        "7",
+		"FIELD_PRIORITY":    "high",
+	}
+	for name, value := range expected {
+		got := fields[name]
+		if name == "CODE_FILE" {
+			got = filepath.Base(got)
+		}
+		if got != value {
+			t.Errorf("field %s expected %q, but got %q", name, value, got)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FUNC"], ".Test_JournalHandler") {
+		t.Errorf("CODE_FUNC field expected to be the test function, but got %q", fields["CODE_FUNC"])
+	}
+	if fields["CODE_LINE"] == "" {
+		t.Errorf("CODE_LINE field expected")
+	}
+}
+
+func Test_JournalHandler_largeEntry(t *testing.T) {
+	t.Parallel()
+
+	conn, path := listenJournal(t)
+	h, err := DialJournal(path, JournalOpts{Identifier: "app"})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+
+	msg := strings.Repeat("a", 1<<20)
+	if err := h.Handle(Record{Level: LevelInfo, Message: msg}); err != nil {
+		t.Fatalf("failed to handle: %s", err)
+	}
+	if fields := readJournalEntry(t, conn); fields["MESSAGE"] != msg {
+		t.Errorf("message of %d bytes expected, but got %d bytes", len(msg), len(fields["MESSAGE"]))
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if err := h.Handle(Record{Level: LevelInfo}); err != ErrClosed {
+		t.Errorf("error expected %v, but got %v", ErrClosed, err)
+	}
+}
+
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// readJournalEntry reads an entry sent as a datagram or as a file descriptor.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("failed to set deadline: %s", err)
+	}
+	buf, oob := make([]byte, 1<<16), make([]byte, 1024)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	data := buf[:n]
+	if oobn > 0 {
+		data = readJournalFile(t, oob[:oobn])
+	}
+
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid entry %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func readJournalFile(t *testing.T, oob []byte) []byte {
+	t.Helper()
+
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("failed to parse control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("failed to parse file descriptor: %v", err)
+	}
+	file := os.NewFile(uintptr(fds[0]), "journal")
+	defer file.Close()
+
+	// F_GET_SEALS
+	seals, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), 0x40a, 0)
+	if errno != 0 || seals != journalFileSeals {
+		t.Errorf("sealed memfd expected, but got seals %#x: %v", seals, errno)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek: %s", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	return data
+}
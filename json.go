@@ -0,0 +1,205 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSON keys of the predefined values.
+const (
+	JSONTimeKey    = "time"
+	JSONLevelKey   = "level"
+	JSONNameKey    = "logger"
+	JSONCallerKey  = "caller"
+	JSONMessageKey = "msg"
+	JSONLabelsKey  = "labels"
+)
+
+// reservedFieldPrefix is added to keys of fields, which match the keys of the predefined values,
+// so the fields do not replace them, e.g. `msg` -> `fields.msg`.
+const reservedFieldPrefix = "fields."
+
+// encodeJSON writes a record as a JSON object followed by a new line:
+//
+//	{"time":"2025-03-22T15:07:50.348957+01:00","level":"info","msg":"updated","labels":["worker-1"],"user":"1000"}
+//
+// Fields with the keys of the predefined values are prefixed, e.g. `fields.msg`.
+func encodeJSON(buf *bytes.Buffer, r *Record, flags int) {
+	buf.WriteByte('{')
+	if t, ok := encodeTime(r.Time, flags); ok {
+		appendJSONKey(buf, JSONTimeKey)
+		appendJSONString(buf, t)
+	}
+	appendJSONKey(buf, JSONLevelKey)
+	appendJSONString(buf, r.LevelName)
+	if r.Name != "" {
+		appendJSONKey(buf, JSONNameKey)
+		appendJSONString(buf, r.Name)
+	}
+	if caller, ok := encodeCaller(r, flags); ok {
+		appendJSONKey(buf, JSONCallerKey)
+		appendJSONString(buf, caller)
+	}
+	appendJSONKey(buf, JSONMessageKey)
+	appendJSONString(buf, r.Message)
+	if len(r.Labels) > 0 {
+		appendJSONKey(buf, JSONLabelsKey)
+		buf.WriteByte('[')
+		for i, label := range r.Labels {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			appendJSONString(buf, label)
+		}
+		buf.WriteByte(']')
+	}
+	for _, field := range r.Fields {
+		appendJSONKey(buf, jsonFieldKey(field.Key))
+		appendJSONValue(buf, field.Value)
+	}
+	buf.WriteString("}\n")
+}
+
+// jsonFieldKey prefixes the key of a field, which matches a key of the predefined values.
+func jsonFieldKey(key string) string {
+	switch key {
+	case JSONTimeKey, JSONLevelKey, JSONNameKey, JSONCallerKey, JSONMessageKey, JSONLabelsKey:
+		return reservedFieldPrefix + key
+	}
+	return key
+}
+
+func appendJSONKey(buf *bytes.Buffer, key string) {
+	if buf.Len() > 0 {
+		if last := buf.Bytes()[buf.Len()-1]; last != '{' {
+			buf.WriteByte(',')
+		}
+	}
+	appendJSONString(buf, key)
+	buf.WriteByte(':')
+}
+
+func appendJSONValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendJSONString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case float32:
+		appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		appendJSONFloat(buf, v, 64)
+	case time.Time:
+		appendJSONString(buf, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		appendJSONString(buf, v.String())
+	case error:
+		appendJSONString(buf, v.Error())
+	case json.Marshaler:
+		appendJSONMarshaled(buf, v)
+	case fmt.Stringer:
+		appendJSONString(buf, v.String())
+	default:
+		appendJSONMarshaled(buf, v)
+	}
+}
+
+func appendJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// JSON has no representation of NaN and infinity
+		appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bitSize))
+		return
+	}
+	buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+func appendJSONMarshaled(buf *bytes.Buffer, value any) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		appendJSONString(buf, fmt.Sprint(value))
+		return
+	}
+	buf.Write(b)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString writes a quoted JSON string, unlike json.Marshal it does not escape HTML characters.
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf.WriteString(s[start:i])
+				buf.WriteString(`\ufffd`)
+				i += size
+				start = i
+				continue
+			}
+			if r == '\u2028' || r == '\u2029' {
+				buf.WriteString(s[start:i])
+				buf.WriteString(`\u202`)
+				buf.WriteByte(hexDigits[r&0xf])
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+		buf.WriteString(s[start:i])
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
@@ -0,0 +1,166 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_appendJSONString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: `""`,
+		},
+		{
+			name:     "simple",
+			value:    "message",
+			expected: `"message"`,
+		},
+		{
+			name:     "quotes-and-backslash",
+			value:    `a "b" \c`,
+			expected: `"a \"b\" \\c"`,
+		},
+		{
+			name:     "control-characters",
+			value:    "a\nb\tc\x01",
+			expected: `"a\nb\tc\u0001"`,
+		},
+		{
+			name:     "html",
+			value:    "<a&b>",
+			expected: `"<a&b>"`,
+		},
+		{
+			name:     "unicode",
+			value:    "привіт\u2028",
+			expected: `"привіт\u2028"`,
+		},
+		{
+			name:     "invalid-utf8",
+			value:    "a\xffb",
+			expected: `"a\ufffdb"`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			appendJSONString(buf, test.value)
+			if buf.String() != test.expected {
+				t.Errorf("expected %s, but got %s", test.expected, buf.String())
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("invalid JSON %s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_encodeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		record   Record
+		flags    int
+		expected string
+	}{
+		{
+			name:     "no-flags",
+			record:   Record{LevelName: "info", Message: "message"},
+			flags:    0,
+			expected: `{"level":"info","msg":"message"}` + "\n",
+		},
+		{
+			name: "utc-time",
+			record: Record{
+				Time:      time.Date(2025, 3, 22, 15, 7, 50, 348957000, time.FixedZone("CET", 3600)),
+				LevelName: "info",
+				Message:   "message",
+			},
+			flags:    log.LstdFlags | log.Lmicroseconds | log.LUTC,
+			expected: `{"time":"2025-03-22T14:07:50.348957Z","level":"info","msg":"message"}` + "\n",
+		},
+		{
+			name: "labels-and-fields",
+			record: Record{
+				LevelName: "error",
+				Labels:    []string{"worker-1"},
+				Fields:    []Field{String("user", "1000"), Int("attempt", 3), Err(errors.New("failed")), Any("tags", []string{"a"})},
+				Message:   "message",
+			},
+			flags:    0,
+			expected: `{"level":"error","msg":"message","labels":["worker-1"],"user":"1000","attempt":3,"error":"failed","tags":["a"]}` + "\n",
+		},
+		{
+			name: "reserved-keys",
+			record: Record{
+				LevelName: "info",
+				Labels:    []string{"worker-1"},
+				Fields:    []Field{String("msg", "y"), String("level", "z"), String("labels", "l"), String("time", "t")},
+				Message:   "x",
+			},
+			flags:    0,
+			expected: `{"level":"info","msg":"x","labels":["worker-1"],"fields.msg":"y","fields.level":"z","fields.labels":"l","fields.time":"t"}` + "\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			encodeJSON(buf, &test.record, test.flags)
+			if buf.String() != test.expected {
+				t.Errorf("expected %s, but got %s", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	logger := New(Writer(buf), JSON(), FileAndLine(), Labels("worker-1", "cpu=100%"))
+	logger.Infow("updated", "user", 1000)
+
+	var result map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %s", buf.String(), err)
+	}
+	if result[JSONMessageKey] != "updated" {
+		t.Errorf("message expected %q, but got %v", "updated", result[JSONMessageKey])
+	}
+	if result[JSONLevelKey] != LevelNameInfo {
+		t.Errorf("level expected %q, but got %v", LevelNameInfo, result[JSONLevelKey])
+	}
+	if result["user"] != float64(1000) {
+		t.Errorf("user expected %d, but got %v", 1000, result["user"])
+	}
+	if caller, _ := result[JSONCallerKey].(string); !strings.HasPrefix(caller, "json_test.go:") {
+		t.Errorf("caller expected in json_test.go, but got %v", result[JSONCallerKey])
+	}
+	if _, ok := result[JSONTimeKey]; !ok {
+		t.Errorf("time expected, but got %s", buf.String())
+	}
+	if labels := fmt.Sprint(result[JSONLabelsKey]); labels != "[worker-1 cpu=100%]" {
+		t.Errorf("raw labels expected, but got %s", labels)
+	}
+}
@@ -2,11 +2,13 @@ package log
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 type labels struct {
 	values    []string
+	fields    []Field
 	separator string
 	format    string
 	formatted string
@@ -26,6 +28,7 @@ func (l labels) clear() labels {
 	}
 	return labels{
 		values:    nil,
+		fields:    nil,
 		separator: l.separator,
 		format:    l.format,
 		formatted: "",
@@ -38,34 +41,49 @@ func (l labels) add(newValues ...string) labels {
 		// probably empty values were added
 		return l
 	}
-	return buildLabels(l.format, values, l.separator)
+	return buildFieldLabels(l.format, values, l.fields, l.separator)
+}
+
+func (l labels) addFields(newFields ...Field) labels {
+	fields := joinFields(l.fields, newFields)
+	if len(fields) == len(l.fields) {
+		// probably fields without keys were added
+		return l
+	}
+	return buildFieldLabels(l.format, l.values, fields, l.separator)
 }
 
 func (l labels) setSeparator(sep string) labels {
 	if l.separator == sep {
 		return l
 	}
-	return buildLabels(l.format, l.values, sep)
+	return buildFieldLabels(l.format, l.values, l.fields, sep)
 }
 
 func (l labels) setFormat(newFormat string) labels {
 	if l.format == newFormat {
 		return l
 	}
-	return buildLabels(newFormat, l.values, l.separator)
+	return buildFieldLabels(newFormat, l.values, l.fields, l.separator)
 }
 
 func buildLabels(format string, values []string, sep string) labels {
+	return buildFieldLabels(format, values, nil, sep)
+}
+
+func buildFieldLabels(format string, values []string, fields []Field, sep string) labels {
 	formatted := ""
-	if len(values) > 0 {
-		formatted = format
+	if len(values) > 0 || len(fields) > 0 {
+		// the format is escaped by parseLabelsFormat, the values are kept raw
+		formatted = strings.ReplaceAll(format, "%%", "%")
 		if strings.Contains(format, "%[1]s") {
-			formatted = fmt.Sprintf(format, strings.Join(values, sep))
+			formatted = fmt.Sprintf(format, joinLabelsText(values, fields, sep))
 		}
 	}
 
 	return labels{
 		values:    values,
+		fields:    fields,
 		separator: sep,
 		format:    format,
 		formatted: formatted,
@@ -79,7 +97,7 @@ func copyLabels(labels []string) []string {
 	newLabels := make([]string, 0, len(labels))
 	for _, label := range labels {
 		if label != "" {
-			newLabels = append(newLabels, escapeFormats(label))
+			newLabels = append(newLabels, label)
 		}
 	}
 	return newLabels
@@ -92,6 +110,9 @@ func parseLabelsFormat(format string) string {
 
 // ClearLabels remove all the labels and returns another instance of Logger, so the original logger is not affected and keeps all the labels.
 func ClearLabels(l Logger) Logger {
+	if log, ok := l.(*slogLogger); ok {
+		return log.clearLabels()
+	}
 	log, ok := l.(*logger)
 	if !ok {
 		return l
@@ -101,13 +122,20 @@ func ClearLabels(l Logger) Logger {
 		format:     log.format.clearLabels(),
 		levelNames: log.levelNames,
 		labels:     log.labels.clear(),
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
 
 // WithLabels adds label(s) returns another instance of Logger,
 // so the original logger is not affected and keeps previous set of labels.
 func WithLabels(l Logger, labels ...string) Logger {
+	if log, ok := l.(*slogLogger); ok {
+		return log.withLabels(labels)
+	}
 	log, ok := l.(*logger)
 	if !ok {
 		return l
@@ -118,7 +146,11 @@ func WithLabels(l Logger, labels ...string) Logger {
 		format:     log.format.withLabels(newLabels.notEmpty()),
 		levelNames: log.levelNames,
 		labels:     newLabels,
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
 
@@ -134,7 +166,11 @@ func WithLabelSeparator(l Logger, sep string) Logger {
 		format:     log.format,
 		levelNames: log.levelNames,
 		labels:     log.labels.setSeparator(sep),
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
 
@@ -150,7 +186,11 @@ func WithLabelsFormat(l Logger, newFormat string) Logger {
 		format:     log.format,
 		levelNames: log.levelNames,
 		labels:     log.labels.setFormat(parseLabelsFormat(newFormat)),
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
 
@@ -164,3 +204,53 @@ func joinLabels(labels []string, newLabels []string) []string {
 	}
 	return result
 }
+
+func joinFields(fields []Field, newFields []Field) []Field {
+	result := make([]Field, 0, len(fields)+len(newFields))
+	result = append(result, fields...)
+	for _, field := range newFields {
+		if field.Key != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// labelFields converts positional labels to fields, so they can be encoded as key/value pairs.
+//
+// Labels like `key=value` are split into a key and a value,
+// other labels get a key with the label index, e.g. `label0`.
+func labelFields(labels []string) []Field {
+	if len(labels) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(labels))
+	for i, label := range labels {
+		if key, value, ok := strings.Cut(label, "="); ok && key != "" && !strings.ContainsAny(key, " \t\"") {
+			fields = append(fields, Field{Key: key, Value: value})
+			continue
+		}
+		fields = append(fields, Field{Key: "label" + strconv.Itoa(i), Value: label})
+	}
+	return fields
+}
+
+func joinLabelsText(values []string, fields []Field, sep string) string {
+	if len(fields) == 0 {
+		return strings.Join(values, sep)
+	}
+	var b strings.Builder
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(value)
+	}
+	for i, field := range fields {
+		if i > 0 || len(values) > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(field.String())
+	}
+	return b.String()
+}
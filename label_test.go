@@ -103,6 +103,20 @@ func Test_buildLabels(t *testing.T) {
 			separator: "-",
 			expected:  "%s A-B-C %d %[1]s",
 		},
+		{
+			name:      "labels-with-percents",
+			format:    "${labels}",
+			values:    []string{"cpu=100%", "%d"},
+			separator: " ",
+			expected:  "cpu=100% %d",
+		},
+		{
+			name:      "format-without-placeholder",
+			format:    "100%",
+			values:    []string{"A"},
+			separator: " ",
+			expected:  "100%",
+		},
 	}
 	for i := range tests {
 		test := tests[i]
@@ -147,3 +161,45 @@ func Test_labels(t *testing.T) {
 		t.Fatalf("new labels formatted expected %q, but got %q", "", newLabels.formatted)
 	}
 }
+
+func Test_labelFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		labels   []string
+		expected []Field
+	}{
+		{
+			name:     "empty",
+			labels:   nil,
+			expected: nil,
+		},
+		{
+			name:     "key-value",
+			labels:   []string{"user=1000", "trace=a=b"},
+			expected: []Field{{Key: "user", Value: "1000"}, {Key: "trace", Value: "a=b"}},
+		},
+		{
+			name:     "positional",
+			labels:   []string{"worker-1", "user:1000", "=empty-key", "user id=1"},
+			expected: []Field{{Key: "label0", Value: "worker-1"}, {Key: "label1", Value: "user:1000"}, {Key: "label2", Value: "=empty-key"}, {Key: "label3", Value: "user id=1"}},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := labelFields(test.labels)
+			if len(result) != len(test.expected) {
+				t.Fatalf("expected %#v, but got %#v", test.expected, result)
+			}
+			for i := range result {
+				if result[i] != test.expected[i] {
+					t.Errorf("expected %#v, but got %#v", test.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package log
 
-import "strings"
+import (
+	"strings"
+	"sync/atomic"
+)
 
 type Level int
 
@@ -66,6 +69,16 @@ func buildLevelNames(opts Opts) map[Level]string {
 	return levelNames
 }
 
+// parseLevelName finds the level by its name ignoring the case.
+func parseLevelName(levelNames map[Level]string, name string) (Level, bool) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(levelName, name) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
 func copyLevelNames(m map[Level]string) map[Level]string {
 	levelNames := make(map[Level]string, LevelTrace)
 	for level := LevelFatal; level <= LevelTrace; level++ {
@@ -78,24 +91,60 @@ func copyLevelNames(m map[Level]string) map[Level]string {
 	return levelNames
 }
 
+// AtomicLevel is a log level that can be changed concurrently.
+//
+// All the loggers derived from a logger by WithLabels, WithFormat, ClearLabels etc. share the same level,
+// so changing it turns on or off the logs of all of them at once.
+type AtomicLevel struct {
+	level atomic.Int32
+}
+
+// NewAtomicLevel creates an AtomicLevel with the initial level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.SetLevel(level)
+	return a
+}
+
+// Level returns the current log level.
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// SetLevel changes the log level of all the loggers sharing it.
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.level.Store(int32(normalizeLevel(level)))
+}
+
+func buildLevel(opts *Opts) *AtomicLevel {
+	if opts.AtomicLevel != nil {
+		return opts.AtomicLevel
+	}
+	return NewAtomicLevel(opts.MinLevel)
+}
+
 // WithLevel returns a new logger from existing with a new log level,
 // the original Logger keeps an original log level.
+//
+// The new logger does not share the level with the original logger anymore.
 func WithLevel(l Logger, newLevel Level) Logger {
+	if log, ok := l.(*slogLogger); ok {
+		return log.withLevel(normalizeLevel(newLevel))
+	}
 	log, ok := l.(*logger)
 	if !ok {
 		return l
 	}
 
-	newLevel = normalizeLevel(newLevel)
-	if log.level == newLevel {
-		return log
-	}
-
 	return &logger{
-		level:      newLevel,
+		level:      NewAtomicLevel(newLevel),
 		format:     log.format,
 		levelNames: log.levelNames,
 		labels:     log.labels,
-		logger:     log.logger,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       log.name,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
 	}
 }
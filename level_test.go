@@ -1,6 +1,10 @@
 package log
 
-import "testing"
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
 
 func Test_normalizeLevel(t *testing.T) {
 	t.Parallel()
@@ -68,3 +72,96 @@ func Test_normalizeLevel(t *testing.T) {
 		})
 	}
 }
+
+func Test_AtomicLevel(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	level := NewAtomicLevel(LevelInfo)
+	logger := New(Writer(buf), Flags(0), SharedLevel(level))
+	labeled := WithLabels(logger, "user=1000")
+	formatted := WithFormat(ClearLabels(labeled), "${level}: ${msg}")
+	detached := WithLevel(labeled, LevelWarn)
+
+	labeled.Debug("not logged")
+	level.SetLevel(LevelDebug)
+	labeled.Debug("labeled")
+	formatted.Debug("formatted")
+	detached.Info("not logged")
+	level.SetLevel(LevelError)
+	logger.Warn("not logged")
+	detached.Warn("detached")
+
+	expected := "[debug] user=1000 labeled\ndebug: formatted\n[warn] user=1000 detached\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+
+	level.SetLevel(100)
+	if level.Level() != LevelTrace {
+		t.Errorf("level expected to be normalized to %d, but got %d", LevelTrace, level.Level())
+	}
+
+	ByLevelName("debug", SharedLevel(level))
+	if level.Level() != LevelDebug {
+		t.Errorf("level expected to be set by name to %d, but got %d", LevelDebug, level.Level())
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(level *AtomicLevel, newLevel Level) {
+			defer wg.Done()
+			level.SetLevel(newLevel)
+			_ = level.Level()
+		}(level, Level(i%int(LevelTrace)+1))
+	}
+	wg.Wait()
+}
+
+func Test_parseLevelName(t *testing.T) {
+	t.Parallel()
+
+	levelNames := copyLevelNames(map[Level]string{LevelTrace: "TRC"})
+	tests := []struct {
+		name     string
+		level    string
+		expected Level
+		ok       bool
+	}{
+		{
+			name:     "default-name",
+			level:    "debug",
+			expected: LevelDebug,
+			ok:       true,
+		},
+		{
+			name:     "upper-case",
+			level:    "WARN",
+			expected: LevelWarn,
+			ok:       true,
+		},
+		{
+			name:     "custom-name",
+			level:    "trc",
+			expected: LevelTrace,
+			ok:       true,
+		},
+		{
+			name:  "unknown",
+			level: "trace",
+			ok:    false,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, ok := parseLevelName(levelNames, test.level)
+			if ok != test.ok || result != test.expected {
+				t.Errorf("expected %d %t, but got %d %t", test.expected, test.ok, result, ok)
+			}
+		})
+	}
+}
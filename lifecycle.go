@@ -0,0 +1,97 @@
+package log
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// fatalSyncTimeout limits the time Fatal waits for the handler to be synced before the exit.
+const fatalSyncTimeout = 5 * time.Second
+
+type syncer interface {
+	Sync() error
+}
+
+// Sync flushes buffered records of the logger and commits the data of its writer, e.g. *os.File,
+// it is useful to call it before the application exits.
+//
+// The handler is shared by all the loggers derived from the logger, so they all are synced.
+func Sync(l Logger) error {
+	log, ok := l.(*logger)
+	if !ok {
+		return nil
+	}
+	return syncHandler(log.handler)
+}
+
+// Close flushes buffered records and closes the handler of the logger and its writer,
+// standard output and error are not closed. The logger should not be used after Close.
+//
+// The handler is shared by all the loggers derived from the logger, so they all are closed.
+func Close(l Logger) error {
+	log, ok := l.(*logger)
+	if !ok {
+		return nil
+	}
+	return closeHandler(log.handler)
+}
+
+func syncHandler(h Handler) error {
+	if s, ok := h.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// syncHandlerTimeout syncs the handler, but does not wait longer than the timeout.
+func syncHandlerTimeout(h Handler, timeout time.Duration) error {
+	if _, ok := h.(syncer); !ok {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- syncHandler(h)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+func closeHandler(h Handler) error {
+	if c, ok := h.(io.Closer); ok {
+		return c.Close()
+	}
+	return syncHandler(h)
+}
+
+func syncWriter(w io.Writer) error {
+	if isStdStream(w) {
+		// syncing of terminals and pipes fails on some systems
+		return nil
+	}
+	if s, ok := w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+func closeWriter(w io.Writer) error {
+	if isStdStream(w) {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func isStdStream(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
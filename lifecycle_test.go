@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// testWriter counts Sync and Close calls.
+type testWriter struct {
+	bytes.Buffer
+	synced int
+	closed int
+}
+
+func (w *testWriter) Sync() error {
+	w.synced++
+	return nil
+}
+
+func (w *testWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func Test_Sync_Close(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Opt
+	}{
+		{
+			name: "text",
+			opts: nil,
+		},
+		{
+			name: "json",
+			opts: []Opt{JSON()},
+		},
+		{
+			name: "async",
+			opts: []Opt{Async(AsyncOpts{})},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := &testWriter{}
+			logger := WithLabels(New(append(test.opts, Writer(w))...), "user=1000")
+			logger.Info("message")
+			if err := Sync(logger); err != nil {
+				t.Fatalf("failed to sync: %s", err)
+			}
+			if w.Len() == 0 {
+				t.Errorf("records expected to be written on sync")
+			}
+			if err := Close(logger); err != nil {
+				t.Fatalf("failed to close: %s", err)
+			}
+			if w.synced != 1 || w.closed != 1 {
+				t.Errorf("writer expected to be synced and closed once, but got %d and %d", w.synced, w.closed)
+			}
+		})
+	}
+}
+
+// slowSyncHandler syncs longer than the test timeout.
+type slowSyncHandler struct {
+	testHandler
+}
+
+func (h *slowSyncHandler) Sync() error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func Test_syncHandlerTimeout(t *testing.T) {
+	t.Parallel()
+
+	if err := syncHandlerTimeout(&slowSyncHandler{}, 10*time.Millisecond); err != os.ErrDeadlineExceeded {
+		t.Errorf("expected %v, but got %v", os.ErrDeadlineExceeded, err)
+	}
+	if err := syncHandlerTimeout(&testHandler{}, 10*time.Millisecond); err != nil {
+		t.Errorf("handler without Sync expected to be skipped, but got %v", err)
+	}
+}
+
+func Test_closeWriter_std(t *testing.T) {
+	t.Parallel()
+
+	if err := Close(New(Writer(os.Stderr))); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if _, err := os.Stderr.Stat(); err != nil {
+		t.Errorf("stderr expected to stay open, but got %s", err)
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Logfmt keys of the predefined values.
+const (
+	LogfmtTimeKey    = "ts"
+	LogfmtLevelKey   = "level"
+	LogfmtNameKey    = "logger"
+	LogfmtCallerKey  = "caller"
+	LogfmtMessageKey = "msg"
+)
+
+// encodeLogfmt writes a record as logfmt line:
+//
+//	ts=2025-03-22T15:07:50.348957+01:00 level=info msg="user updated" worker=1 label1=primary user=1000
+//
+// Labels and fields with the keys of the predefined values are prefixed, e.g. `fields.msg`.
+func encodeLogfmt(buf *bytes.Buffer, r *Record, flags int) {
+	if t, ok := encodeTime(r.Time, flags); ok {
+		appendLogfmtPair(buf, LogfmtTimeKey, t)
+	}
+	appendLogfmtPair(buf, LogfmtLevelKey, r.LevelName)
+	if r.Name != "" {
+		appendLogfmtPair(buf, LogfmtNameKey, r.Name)
+	}
+	if caller, ok := encodeCaller(r, flags); ok {
+		appendLogfmtPair(buf, LogfmtCallerKey, caller)
+	}
+	appendLogfmtPair(buf, LogfmtMessageKey, r.Message)
+	for _, field := range labelFields(r.Labels) {
+		appendLogfmtPair(buf, logfmtFieldKey(field.Key), formatFieldValue(field.Value))
+	}
+	for _, field := range r.Fields {
+		appendLogfmtPair(buf, logfmtFieldKey(field.Key), formatFieldValue(field.Value))
+	}
+	buf.WriteByte('\n')
+}
+
+// logfmtFieldKey prefixes the key of a label or a field, which matches a key of the predefined values.
+func logfmtFieldKey(key string) string {
+	switch key {
+	case LogfmtTimeKey, LogfmtLevelKey, LogfmtNameKey, LogfmtCallerKey, LogfmtMessageKey:
+		return reservedFieldPrefix + key
+	}
+	return key
+}
+
+func appendLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(quoteFieldValue(value))
+}
+
+// logfmtKey replaces characters which are not allowed in logfmt keys with underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == 0xfffd {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_encodeLogfmt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		record   Record
+		flags    int
+		expected string
+	}{
+		{
+			name:     "no-flags",
+			record:   Record{LevelName: "info", Message: "message"},
+			flags:    0,
+			expected: "level=info msg=message\n",
+		},
+		{
+			name: "utc-time",
+			record: Record{
+				Time:      time.Date(2025, 3, 22, 15, 7, 50, 0, time.FixedZone("CET", 3600)),
+				LevelName: "INFO",
+				Message:   "user updated",
+			},
+			flags:    log.LstdFlags | log.LUTC,
+			expected: "ts=2025-03-22T14:07:50Z level=INFO msg=\"user updated\"\n",
+		},
+		{
+			name: "labels-and-fields",
+			record: Record{
+				LevelName: "error",
+				Labels:    []string{"worker-1", "user=1000"},
+				Fields:    []Field{Err(errors.New(`file "a" not found`)), String("path", `C:\tmp`), String("empty", "")},
+				Message:   "line1\nline2",
+			},
+			flags:    0,
+			expected: `level=error msg="line1\nline2" label0=worker-1 user=1000 error="file \"a\" not found" path="C:\\tmp" empty=""` + "\n",
+		},
+		{
+			name: "invalid-keys",
+			record: Record{
+				LevelName: "info",
+				Fields:    []Field{String("user id", "1"), String("a=b", "2")},
+				Message:   "message",
+			},
+			flags:    0,
+			expected: "level=info msg=message user_id=1 a_b=2\n",
+		},
+		{
+			name: "reserved-keys",
+			record: Record{
+				LevelName: "info",
+				Labels:    []string{"msg=a"},
+				Fields:    []Field{String("msg", "y"), String("ts", "t"), String("level", "z")},
+				Message:   "x",
+			},
+			flags:    0,
+			expected: "level=info msg=x fields.msg=a fields.msg=y fields.ts=t fields.level=z\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			encodeLogfmt(buf, &test.record, test.flags)
+			if buf.String() != test.expected {
+				t.Errorf("expected %q, but got %q", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func Test_Logfmt(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	logger := ByOptions(Opts{Writer: buf, Encoding: EncodingLogfmt, Flags: log.Lshortfile, UpperCase: true})
+	logger.Warnw("slow request", "elapsed", time.Second)
+
+	result := trimCallerLine(t, buf.String())
+	if result != "level=WARN caller=logfmt_test.go msg=\"slow request\" elapsed=1s\n" {
+		t.Errorf("unexpected logfmt line %q", buf.String())
+	}
+}
+
+// trimCallerLine removes the line number from the caller.
+func trimCallerLine(t *testing.T, line string) string {
+	t.Helper()
+
+	start := strings.Index(line, "logfmt_test.go:")
+	if start < 0 {
+		t.Fatalf("caller is not found in %q", line)
+	}
+	end := start + strings.IndexByte(line[start:], ' ')
+	return line[:start] + "logfmt_test.go" + line[end:]
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // New creates a Logger instance with provided options.
@@ -13,60 +16,52 @@ func New(opts ...Opt) Logger {
 	for _, opt := range opts {
 		opt(options)
 	}
-	labels := buildLabels(parseLabelsFormat(options.LabelsFormat), copyLabels(options.Labels), options.LabelsSeparator)
-	return &logger{
-		level:      options.MinLevel,
-		format:     buildFormat(options.Format, labels.notEmpty()),
-		levelNames: buildLevelNames(*options),
-		logger:     buildLogger(options),
-		labels:     labels,
-	}
+	return newLogger(options)
 }
 
 // ByOptions creates a Logger using provided options.
 func ByOptions(opts Opts) Logger {
-	options := mergeOpts(defaultOpts(), &opts)
-	labels := buildLabels(parseLabelsFormat(options.LabelsFormat), copyLabels(options.Labels), options.LabelsSeparator)
-	return &logger{
-		level:      options.MinLevel,
-		format:     buildFormat(options.Format, labels.notEmpty()),
-		levelNames: buildLevelNames(*options),
-		logger:     buildLogger(options),
-		labels:     labels,
-	}
+	return newLogger(mergeOpts(defaultOpts(), &opts))
 }
 
 // ByLevelName creates a Logger with log level name provided and options.
 //
 // It can be useful when log is created from some config.
 //
-// If level name is not recognised, then opts.MinLevel is used as a backup.
+// If level name is not recognised, then opts.MinLevel is used as a backup,
+// the shared level is set to the recognised level only.
 func ByLevelName(levelName string, opts ...Opt) Logger {
 	options := defaultOpts()
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	levelNames := buildLevelNames(*options)
 	if options.UpperCase {
 		levelName = strings.ToUpper(levelName)
 	}
 
-	level := options.MinLevel
-	for lvl, name := range levelNames {
+	log := newLogger(options)
+	for level, name := range log.levelNames {
 		if name == levelName {
-			level = lvl
+			log.level.SetLevel(level)
 			break
 		}
 	}
+	return log
+}
 
-	labels := buildLabels(parseLabelsFormat(options.LabelsFormat), copyLabels(options.Labels), options.LabelsSeparator)
+func newLogger(options *Opts) *logger {
+	levelNames := buildLevelNames(*options)
+	labels := buildFieldLabels(parseLabelsFormat(options.LabelsFormat), copyLabels(options.Labels), copyFields(options.Fields), options.LabelsSeparator)
 	return &logger{
-		level:      level,
+		level:      buildLevel(options),
 		format:     buildFormat(options.Format, labels.notEmpty()),
 		levelNames: levelNames,
-		logger:     buildLogger(options),
 		labels:     labels,
+		handler:    buildHandler(options),
+		seq:        &atomic.Uint64{},
+		nameLevels: buildNameLevels(options.NameLevels, levelNames),
+		exiter:     buildExiter(options),
 	}
 }
 
@@ -83,82 +78,143 @@ func buildLogger(opts *Opts) *log.Logger {
 }
 
 type logger struct {
-	level      Level
+	level      *AtomicLevel
 	format     format
 	levelNames map[Level]string
 	labels     labels
-	logger     *log.Logger
-}
-
-func (l *logger) Log(lvl Level, v ...any)            { l.log(normalizeLevel(lvl), v...) }
-func (l *logger) Logf(lvl Level, f string, v ...any) { l.logf(normalizeLevel(lvl), f, v...) }
-func (l *logger) Trace(v ...any)                     { l.log(LevelTrace, v...) }
-func (l *logger) Tracef(f string, v ...any)          { l.logf(LevelTrace, f, v...) }
-func (l *logger) Debug(v ...any)                     { l.log(LevelDebug, v...) }
-func (l *logger) Debugf(f string, v ...any)          { l.logf(LevelDebug, f, v...) }
-func (l *logger) Info(v ...any)                      { l.log(LevelInfo, v...) }
-func (l *logger) Infof(f string, v ...any)           { l.logf(LevelInfo, f, v...) }
-func (l *logger) Notice(v ...any)                    { l.log(LevelInfo, v...) }
-func (l *logger) Noticef(f string, v ...any)         { l.logf(LevelInfo, f, v...) }
-func (l *logger) Warn(v ...any)                      { l.log(LevelWarn, v...) }
-func (l *logger) Warnf(f string, v ...any)           { l.logf(LevelWarn, f, v...) }
-func (l *logger) Error(v ...any)                     { l.log(LevelError, v...) }
-func (l *logger) Errorf(f string, v ...any)          { l.logf(LevelError, f, v...) }
-func (l *logger) Panic(v ...any)                     { l.panic(v...) }
-func (l *logger) Panicf(f string, v ...any)          { l.panicf(f, v...) }
-func (l *logger) Fatal(v ...interface{})             { l.fatal(v...) }
-func (l *logger) Fatalf(f string, v ...any)          { l.fatalf(f, v...) }
+	handler    Handler
+	seq        *atomic.Uint64
+	name       string
+	nameLevels nameLevels
+	exiter     *exiter
+}
+
+func (l *logger) Log(lvl Level, v ...any)               { l.log(normalizeLevel(lvl), v...) }
+func (l *logger) Logf(lvl Level, f string, v ...any)    { l.logf(normalizeLevel(lvl), f, v...) }
+func (l *logger) Logw(lvl Level, msg string, kv ...any) { l.logw(normalizeLevel(lvl), msg, kv...) }
+func (l *logger) Trace(v ...any)                        { l.log(LevelTrace, v...) }
+func (l *logger) Tracef(f string, v ...any)             { l.logf(LevelTrace, f, v...) }
+func (l *logger) Tracew(msg string, kv ...any)          { l.logw(LevelTrace, msg, kv...) }
+func (l *logger) Debug(v ...any)                        { l.log(LevelDebug, v...) }
+func (l *logger) Debugf(f string, v ...any)             { l.logf(LevelDebug, f, v...) }
+func (l *logger) Debugw(msg string, kv ...any)          { l.logw(LevelDebug, msg, kv...) }
+func (l *logger) Info(v ...any)                         { l.log(LevelInfo, v...) }
+func (l *logger) Infof(f string, v ...any)              { l.logf(LevelInfo, f, v...) }
+func (l *logger) Infow(msg string, kv ...any)           { l.logw(LevelInfo, msg, kv...) }
+func (l *logger) Notice(v ...any)                       { l.log(LevelInfo, v...) }
+func (l *logger) Noticef(f string, v ...any)            { l.logf(LevelInfo, f, v...) }
+func (l *logger) Noticew(msg string, kv ...any)         { l.logw(LevelInfo, msg, kv...) }
+func (l *logger) Warn(v ...any)                         { l.log(LevelWarn, v...) }
+func (l *logger) Warnf(f string, v ...any)              { l.logf(LevelWarn, f, v...) }
+func (l *logger) Warnw(msg string, kv ...any)           { l.logw(LevelWarn, msg, kv...) }
+func (l *logger) Error(v ...any)                        { l.log(LevelError, v...) }
+func (l *logger) Errorf(f string, v ...any)             { l.logf(LevelError, f, v...) }
+func (l *logger) Errorw(msg string, kv ...any)          { l.logw(LevelError, msg, kv...) }
+func (l *logger) Panic(v ...any)                        { l.panic(v...) }
+func (l *logger) Panicf(f string, v ...any)             { l.panicf(f, v...) }
+func (l *logger) Panicw(msg string, kv ...any)          { l.panicw(msg, kv...) }
+func (l *logger) Fatal(v ...interface{})                { l.fatal(v...) }
+func (l *logger) Fatalf(f string, v ...any)             { l.fatalf(f, v...) }
+func (l *logger) Fatalw(msg string, kv ...any)          { l.fatalw(msg, kv...) }
 
 func (l *logger) log(level Level, v ...any) {
-	if l.level < level || len(v) == 0 {
+	if l.level.Level() < level || len(v) == 0 {
 		return
 	}
 
-	l.logger.Output(3, fmt.Sprintf(l.format.value, l.levelNames[level], l.labels.formatted, fmt.Sprint(v...)))
+	l.output(level, l.labels, fmt.Sprint(v...))
 }
 
 func (l *logger) logf(level Level, f string, v ...any) {
-	if l.level < level {
+	if l.level.Level() < level {
+		return
+	}
+
+	l.output(level, l.labels, fmt.Sprintf(f, v...))
+}
+
+func (l *logger) logw(level Level, msg string, kv ...any) {
+	if l.level.Level() < level {
 		return
 	}
 
-	l.logger.Output(3, fmt.Sprintf(l.format.value, l.levelNames[level], l.labels.formatted, fmt.Sprintf(f, v...)))
+	l.output(level, l.labels.addFields(fieldsFromKeyValues(kv)...), msg)
 }
 
 func (l *logger) panic(v ...any) {
-	if l.level < LevelPanic {
+	if l.level.Level() < LevelPanic {
 		return
 	}
 
-	msg := fmt.Sprintf(l.format.value, l.levelNames[LevelFatal], l.labels.formatted, fmt.Sprint(v...))
-	l.logger.Output(3, msg)
-	panic(msg)
+	panic(l.output(LevelPanic, l.labels, fmt.Sprint(v...)).Text())
 }
 
 func (l *logger) panicf(f string, v ...any) {
-	if l.level < LevelPanic {
+	if l.level.Level() < LevelPanic {
 		return
 	}
 
-	msg := fmt.Sprintf(l.format.value, l.levelNames[LevelFatal], l.labels.formatted, fmt.Sprintf(f, v...))
-	l.logger.Output(3, msg)
-	panic(msg)
+	panic(l.output(LevelPanic, l.labels, fmt.Sprintf(f, v...)).Text())
+}
+
+func (l *logger) panicw(msg string, kv ...any) {
+	if l.level.Level() < LevelPanic {
+		return
+	}
+
+	panic(l.output(LevelPanic, l.labels.addFields(fieldsFromKeyValues(kv)...), msg).Text())
 }
 
 func (l *logger) fatal(v ...any) {
-	if l.level < LevelFatal {
+	if l.level.Level() < LevelFatal {
 		return
 	}
 
-	l.logger.Output(3, fmt.Sprintf(l.format.value, l.levelNames[LevelFatal], l.labels.formatted, fmt.Sprint(v...)))
-	os.Exit(1)
+	l.output(LevelFatal, l.labels, fmt.Sprint(v...))
+	l.exiter.fatal(l.handler)
 }
 
 func (l *logger) fatalf(f string, v ...any) {
-	if l.level < LevelFatal {
+	if l.level.Level() < LevelFatal {
 		return
 	}
 
-	l.logger.Output(3, fmt.Sprintf(l.format.value, l.levelNames[LevelFatal], l.labels.formatted, fmt.Sprintf(f, v...)))
-	os.Exit(1)
+	l.output(LevelFatal, l.labels, fmt.Sprintf(f, v...))
+	l.exiter.fatal(l.handler)
+}
+
+func (l *logger) fatalw(msg string, kv ...any) {
+	if l.level.Level() < LevelFatal {
+		return
+	}
+
+	l.output(LevelFatal, l.labels.addFields(fieldsFromKeyValues(kv)...), msg)
+	l.exiter.fatal(l.handler)
+}
+
+// output passes the message to the handler and returns the record.
+//
+// The caller is counted from the public Logger methods, e.g. Info -> log -> output.
+func (l *logger) output(level Level, labels labels, msg string) Record {
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:])
+	return l.handle(level, labels, msg, time.Now(), pcs[0])
+}
+
+func (l *logger) handle(level Level, labels labels, msg string, t time.Time, pc uintptr) Record {
+	r := Record{
+		Time:      t,
+		Level:     level,
+		LevelName: l.levelNames[level],
+		Labels:    labels.values,
+		Fields:    labels.fields,
+		Message:   msg,
+		Name:      l.name,
+		PC:        pc,
+		Seq:       l.seq.Add(1),
+		format:    &l.format,
+		labels:    &labels,
+	}
+	l.handler.Handle(r)
+	return r
 }
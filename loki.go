@@ -0,0 +1,264 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultLokiTimeout = 10 * time.Second
+
+// LokiOpts configures a LokiHandler, zero values are replaced by defaults.
+type LokiOpts struct {
+	// Labels are static stream labels, e.g. {"app": "api"}, Loki rejects streams without labels.
+	//
+	//	Default: {"job": the executable name}
+	Labels map[string]string
+	// StreamLabels are keys of labels and fields sent as stream labels, other labels and fields are sent in the line.
+	// `level` and `logger` keys make the level name and the logger name stream labels.
+	//
+	//	Example: []string{"level", "env"}
+	StreamLabels []string
+	// Header is added to the requests, e.g. Authorization or X-Scope-OrgID.
+	Header http.Header
+	// Gzip compresses the requests.
+	Gzip bool
+	// Client sends the requests.
+	//
+	//	Default: http.Client with 10s timeout
+	Client *http.Client
+	// Batch configures batching of the records.
+	Batch BatchOpts
+	// Retry configures retries of batches which failed to be sent,
+	// requests rejected with 4xx status except 429 are not retried.
+	Retry RetryOpts
+}
+
+// LokiHandler is a Handler that pushes records to Grafana Loki in JSON.
+//
+// Records are sent in background as batches, the line of each record is a logfmt line,
+// stream labels are taken from LokiOpts:
+//
+//	{"streams":[{"stream":{"app":"api","level":"error"},"values":[["1742656070348957000","msg=failed user=1000"]]}]}
+//
+// Sync waits for the pending records to be sent. LokiHandler is safe for concurrent use.
+type LokiHandler struct {
+	batcher *batcher
+	url     string
+	opts    LokiOpts
+
+	// buffers are used by the batcher goroutine only
+	buf  bytes.Buffer
+	line bytes.Buffer
+	zbuf bytes.Buffer
+}
+
+// NewLokiHandler creates a handler pushing records to the push endpoint, e.g. `http://localhost:3100/loki/api/v1/push`.
+func NewLokiHandler(pushURL string, opts LokiOpts) (*LokiHandler, error) {
+	if _, err := url.ParseRequestURI(pushURL); err != nil {
+		return nil, err
+	}
+	if len(opts.Labels) == 0 {
+		opts.Labels = map[string]string{"job": filepath.Base(os.Args[0])}
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultLokiTimeout}
+	}
+	h := &LokiHandler{
+		url:  pushURL,
+		opts: opts,
+	}
+	h.batcher = newBatcher(opts.Batch, opts.Retry, h.send)
+	return h, nil
+}
+
+// Handle adds the record to the batch.
+func (h *LokiHandler) Handle(r Record) error {
+	return h.batcher.handle(r)
+}
+
+// Sync waits for the pending records to be sent and returns the last error of sending.
+func (h *LokiHandler) Sync() error {
+	return h.batcher.flush()
+}
+
+// Close sends the pending records and stops sending.
+func (h *LokiHandler) Close() error {
+	return h.batcher.close()
+}
+
+// Dropped returns the number of records dropped, because there were too many pending records.
+func (h *LokiHandler) Dropped() uint64 {
+	return h.batcher.dropped.Load()
+}
+
+func (h *LokiHandler) send(records []Record) error {
+	h.encode(records)
+	body := h.buf.Bytes()
+	if h.opts.Gzip {
+		h.zbuf.Reset()
+		w := gzip.NewWriter(&h.zbuf)
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = h.zbuf.Bytes()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for key, values := range h.opts.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return doRequest(h.opts.Client, req, nil)
+}
+
+// lokiStream is a stream of a batch, values are indexes of its records.
+type lokiStream struct {
+	labels  []Field
+	records []int
+}
+
+func (h *LokiHandler) encode(records []Record) {
+	var streams []*lokiStream
+	keys := map[string]*lokiStream{}
+	for i := range records {
+		labels := h.streamLabels(&records[i])
+		key := lokiStreamKey(labels)
+		stream, ok := keys[key]
+		if !ok {
+			stream = &lokiStream{labels: labels}
+			keys[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.records = append(stream.records, i)
+	}
+
+	h.buf.Reset()
+	h.buf.WriteString(`{"streams":[`)
+	for i, stream := range streams {
+		if i > 0 {
+			h.buf.WriteByte(',')
+		}
+		h.buf.WriteString(`{"stream":{`)
+		for _, label := range stream.labels {
+			appendJSONKey(&h.buf, label.Key)
+			appendJSONString(&h.buf, label.Value.(string))
+		}
+		h.buf.WriteString(`},"values":[`)
+		for j, index := range stream.records {
+			if j > 0 {
+				h.buf.WriteByte(',')
+			}
+			r := &records[index]
+			t := r.Time
+			if t.IsZero() {
+				t = time.Now()
+			}
+			h.buf.WriteString(`["`)
+			h.buf.WriteString(strconv.FormatInt(t.UnixNano(), 10))
+			h.buf.WriteString(`",`)
+			h.encodeLine(r)
+			appendJSONString(&h.buf, h.line.String())
+			h.buf.WriteByte(']')
+		}
+		h.buf.WriteString("]}")
+	}
+	h.buf.WriteString("]}")
+}
+
+// streamLabels returns the static labels and the record labels and fields listed in StreamLabels sorted by keys.
+func (h *LokiHandler) streamLabels(r *Record) []Field {
+	labels := make([]Field, 0, len(h.opts.Labels)+len(h.opts.StreamLabels))
+	for key, value := range h.opts.Labels {
+		labels = append(labels, Field{Key: lokiLabelName(key), Value: value})
+	}
+	h.eachField(r, func(key string, value any, stream bool) {
+		if stream {
+			labels = append(labels, Field{Key: lokiLabelName(key), Value: formatFieldValue(value)})
+		}
+	})
+	slices.SortStableFunc(labels, func(a, b Field) int { return strings.Compare(a.Key, b.Key) })
+
+	// the last label of a key is kept, so the record labels override the static ones
+	unique := labels[:0]
+	for i, label := range labels {
+		if i+1 < len(labels) && labels[i+1].Key == label.Key {
+			continue
+		}
+		unique = append(unique, label)
+	}
+	return unique
+}
+
+func lokiStreamKey(labels []Field) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.Key)
+		b.WriteByte('=')
+		b.WriteString(label.Value.(string))
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// encodeLine writes the values which are not stream labels as logfmt line to the line buffer.
+func (h *LokiHandler) encodeLine(r *Record) {
+	h.line.Reset()
+	h.eachField(r, func(key string, value any, stream bool) {
+		if !stream {
+			appendLogfmtPair(&h.line, key, formatFieldValue(value))
+		}
+	})
+}
+
+// eachField calls the function for the level, the logger name, the caller, the message, labels and fields of the record.
+func (h *LokiHandler) eachField(r *Record, fn func(key string, value any, stream bool)) {
+	fn(LogfmtLevelKey, r.LevelName, h.isStreamLabel(LogfmtLevelKey))
+	if r.Name != "" {
+		fn(LogfmtNameKey, r.Name, h.isStreamLabel(LogfmtNameKey))
+	}
+	if frame := r.Frame(); frame.File != "" {
+		fn(LogfmtCallerKey, shortFile(frame.File)+":"+strconv.Itoa(frame.Line), false)
+	}
+	fn(LogfmtMessageKey, strings.TrimRight(r.Message, "\n"), false)
+	for _, field := range labelFields(r.Labels) {
+		fn(field.Key, field.Value, h.isStreamLabel(field.Key))
+	}
+	for _, field := range r.Fields {
+		fn(field.Key, field.Value, h.isStreamLabel(field.Key))
+	}
+}
+
+func (h *LokiHandler) isStreamLabel(key string) bool {
+	return slices.Contains(h.opts.StreamLabels, key)
+}
+
+// lokiLabelName replaces characters which are not allowed in Prometheus label names with underscores.
+func lokiLabelName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
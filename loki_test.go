@@ -0,0 +1,261 @@
+package log
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// lokiServer records pushes and responds with the statuses in order, the last status is repeated.
+type lokiServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	pushes   []lokiPush
+	headers  []http.Header
+	statuses []int
+}
+
+func newLokiServer(t *testing.T, statuses ...int) *lokiServer {
+	t.Helper()
+
+	s := &lokiServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		var push lokiPush
+		if err := json.NewDecoder(body).Decode(&push); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.pushes = append(s.pushes, push)
+		s.headers = append(s.headers, r.Header)
+		status := http.StatusNoContent
+		if len(s.statuses) > 0 {
+			status = s.statuses[0]
+			if len(s.statuses) > 1 {
+				s.statuses = s.statuses[1:]
+			}
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *lokiServer) requests() ([]lokiPush, []http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pushes, s.headers
+}
+
+func Test_LokiHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		gzip bool
+	}{
+		{name: "plain", gzip: false},
+		{name: "gzip", gzip: true},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newLokiServer(t)
+			h, err := NewLokiHandler(server.URL+"/loki/api/v1/push", LokiOpts{
+				Labels:       map[string]string{"app": "api", "env": "dev"},
+				StreamLabels: []string{"level", "env"},
+				Header:       http.Header{"X-Scope-Orgid": []string{"tenant"}},
+				Gzip:         test.gzip,
+				Batch:        BatchOpts{Interval: time.Hour},
+			})
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			logger := New(CustomHandler(h))
+			WithLabels(logger, "env=prod", "worker").Info("first")
+			logger.Errorw("failed", "user", 1000)
+			WithLabels(logger, "env=prod").Info("second")
+			if err := Close(logger); err != nil {
+				t.Fatalf("failed to close: %s", err)
+			}
+
+			pushes, headers := server.requests()
+			if len(pushes) != 1 {
+				t.Fatalf("1 push expected, but got %d", len(pushes))
+			}
+			if headers[0].Get("X-Scope-OrgID") != "tenant" {
+				t.Errorf("header expected to be passed, but got %v", headers[0])
+			}
+
+			type stream struct {
+				labels map[string]string
+				lines  []string
+			}
+			expected := []stream{
+				{
+					labels: map[string]string{"app": "api", "env": "prod", "level": "info"},
+					lines:  []string{"msg=first label1=worker", "msg=second"},
+				},
+				{
+					labels: map[string]string{"app": "api", "env": "dev", "level": "error"},
+					lines:  []string{"msg=failed user=1000"},
+				},
+			}
+			streams := pushes[0].Streams
+			if len(streams) != len(expected) {
+				t.Fatalf("streams expected %d, but got %d: %v", len(expected), len(streams), streams)
+			}
+			for i := range streams {
+				if !reflect.DeepEqual(streams[i].Stream, expected[i].labels) {
+					t.Errorf("stream %d labels expected %v, but got %v", i, expected[i].labels, streams[i].Stream)
+				}
+				if len(streams[i].Values) != len(expected[i].lines) {
+					t.Fatalf("stream %d values expected %d, but got %v", i, len(expected[i].lines), streams[i].Values)
+				}
+				for j, value := range streams[i].Values {
+					if value[0] == "" || strings.Trim(value[0], "0123456789") != "" {
+						t.Errorf("timestamp in nanoseconds expected, but got %q", value[0])
+					}
+					// the caller depends on the test line
+					line := value[1]
+					if caller, rest, ok := strings.Cut(line, " msg="); ok && strings.HasPrefix(caller, "caller=loki_test.go:") {
+						line = "msg=" + rest
+					}
+					if line != expected[i].lines[j] {
+						t.Errorf("stream %d line expected %q, but got %q", i, expected[i].lines[j], value[1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_LokiHandler_defaultLabels(t *testing.T) {
+	t.Parallel()
+
+	server := newLokiServer(t)
+	h, err := NewLokiHandler(server.URL, LokiOpts{Batch: BatchOpts{Interval: time.Hour}})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err)
+	}
+	New(CustomHandler(h)).Info("message")
+	if err := h.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+
+	pushes, _ := server.requests()
+	if len(pushes) != 1 || len(pushes[0].Streams) != 1 {
+		t.Fatalf("1 push with 1 stream expected, but got %v", pushes)
+	}
+	expected := map[string]string{"job": filepath.Base(os.Args[0])}
+	if stream := pushes[0].Streams[0].Stream; !reflect.DeepEqual(stream, expected) {
+		t.Errorf("stream labels expected %v, but got %v", expected, stream)
+	}
+}
+
+func Test_LokiHandler_retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		statuses []int
+		failing  bool
+		requests int
+	}{
+		{
+			name:     "retried",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent},
+			failing:  false,
+			requests: 3,
+		},
+		{
+			name:     "retries-exceeded",
+			statuses: []int{http.StatusInternalServerError},
+			failing:  true,
+			requests: 3,
+		},
+		{
+			name:     "rejected",
+			statuses: []int{http.StatusBadRequest},
+			failing:  true,
+			requests: 1,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newLokiServer(t, test.statuses...)
+			h, err := NewLokiHandler(server.URL, LokiOpts{
+				Batch: BatchOpts{Interval: time.Hour},
+				Retry: RetryOpts{MaxRetries: 2, MinBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			defer h.Close()
+
+			New(CustomHandler(h)).Info("message")
+			if err := h.Sync(); (err != nil) != test.failing {
+				t.Errorf("failing sync expected %t, but got %v", test.failing, err)
+			}
+			if pushes, _ := server.requests(); len(pushes) != test.requests {
+				t.Errorf("requests expected %d, but got %d", test.requests, len(pushes))
+			}
+		})
+	}
+}
+
+func Test_lokiLabelName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "env", expected: "env"},
+		{key: "request-id", expected: "request_id"},
+		{key: "0day", expected: "_0day"},
+		{key: "", expected: "_"},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.key, func(t *testing.T) {
+			t.Parallel()
+
+			if name := lokiLabelName(test.key); name != test.expected {
+				t.Errorf("name expected %q, but got %q", test.expected, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Minimal MessagePack encoder and decoder used by the Fluent Forward protocol.
+
+// msgpackExt is an extension value, e.g. EventTime.
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// errMsgpackInvalid is returned for an unknown format or an invalid length.
+var errMsgpackInvalid = errors.New("log: invalid msgpack")
+
+// msgpackMaxLen limits lengths of decoded values, so a broken input does not allocate too much memory.
+const msgpackMaxLen = 64 << 20
+
+func appendMsgpackNil(buf *bytes.Buffer) {
+	buf.WriteByte(0xc0)
+}
+
+func appendMsgpackBool(buf *bytes.Buffer, v bool) {
+	if v {
+		buf.WriteByte(0xc3)
+		return
+	}
+	buf.WriteByte(0xc2)
+}
+
+func appendMsgpackInt(buf *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0:
+		appendMsgpackUint(buf, uint64(v))
+	case v >= -32:
+		buf.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(v)})
+	case v >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+	}
+}
+
+func appendMsgpackUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v <= math.MaxInt8:
+		buf.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(v)})
+	case v <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, v))
+	}
+}
+
+func appendMsgpackFloat(buf *bytes.Buffer, v float64) {
+	buf.WriteByte(0xcb)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func appendMsgpackString(buf *bytes.Buffer, s string) {
+	appendMsgpackLength(buf, len(s), 0xa0, 31, 0xd9, 0xda, 0xdb)
+	buf.WriteString(s)
+}
+
+func appendMsgpackBin(buf *bytes.Buffer, b []byte) {
+	appendMsgpackLength(buf, len(b), 0, -1, 0xc4, 0xc5, 0xc6)
+	buf.Write(b)
+}
+
+func appendMsgpackArrayHeader(buf *bytes.Buffer, n int) {
+	appendMsgpackLength(buf, n, 0x90, 15, 0, 0xdc, 0xdd)
+}
+
+func appendMsgpackMapHeader(buf *bytes.Buffer, n int) {
+	appendMsgpackLength(buf, n, 0x80, 15, 0, 0xde, 0xdf)
+}
+
+// appendMsgpackLength writes the length by the shortest format,
+// fixMax is -1, when there is no fix format, and len8 is 0, when there is no 8-bit format.
+func appendMsgpackLength(buf *bytes.Buffer, n int, fix byte, fixMax int, len8, len16, len32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint8 && len8 != 0:
+		buf.Write([]byte{len8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(len16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(len32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// appendMsgpackEventTime writes the time as EventTime extension of the Fluent Forward protocol.
+func appendMsgpackEventTime(buf *bytes.Buffer, t time.Time) {
+	buf.Write([]byte{0xd7, 0x00})
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(t.Unix())))
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(t.Nanosecond())))
+}
+
+// appendMsgpackValue writes numbers, booleans and nil as they are, other values as strings.
+func appendMsgpackValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case nil:
+		appendMsgpackNil(buf)
+	case bool:
+		appendMsgpackBool(buf, v)
+	case int:
+		appendMsgpackInt(buf, int64(v))
+	case int8:
+		appendMsgpackInt(buf, int64(v))
+	case int16:
+		appendMsgpackInt(buf, int64(v))
+	case int32:
+		appendMsgpackInt(buf, int64(v))
+	case int64:
+		appendMsgpackInt(buf, v)
+	case uint:
+		appendMsgpackUint(buf, uint64(v))
+	case uint8:
+		appendMsgpackUint(buf, uint64(v))
+	case uint16:
+		appendMsgpackUint(buf, uint64(v))
+	case uint32:
+		appendMsgpackUint(buf, uint64(v))
+	case uint64:
+		appendMsgpackUint(buf, v)
+	case float32:
+		appendMsgpackFloat(buf, float64(v))
+	case float64:
+		appendMsgpackFloat(buf, v)
+	default:
+		appendMsgpackString(buf, formatFieldValue(v))
+	}
+}
+
+// decodeMsgpack reads a value, maps are decoded to map[string]any, arrays to []any,
+// integers to int64, or uint64 when they do not fit, and extensions to msgpackExt.
+func decodeMsgpack(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		b, err := readMsgpackBytes(r, int(c&0x1f))
+		return string(b), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackLength(r, c-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readMsgpackLength(r, c-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackExt(r, n)
+	case 0xca:
+		b, err := readMsgpackBytes(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := readMsgpackBytes(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := readMsgpackBytes(r, 1<<(c-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		v := msgpackUint(b)
+		if v > math.MaxInt64 {
+			return v, nil
+		}
+		return int64(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := readMsgpackBytes(r, 1<<(c-0xd0))
+		if err != nil {
+			return nil, err
+		}
+		// sign extension of the big endian value
+		shift := 64 - 8*len(b)
+		return int64(msgpackUint(b)<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackExt(r, 1<<(c-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackLength(r, c-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		b, err := readMsgpackBytes(r, n)
+		return string(b), err
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLength(r, c-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLength(r, c-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackMap(r, n)
+	}
+	return nil, fmt.Errorf("%w: unknown format 0x%x", errMsgpackInvalid, c)
+}
+
+func decodeMsgpackArray(r *bufio.Reader, n int) ([]any, error) {
+	values := make([]any, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func decodeMsgpackMap(r *bufio.Reader, n int) (map[string]any, error) {
+	values := make(map[string]any, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		values[fmt.Sprint(k)] = v
+	}
+	return values, nil
+}
+
+func readMsgpackExt(r *bufio.Reader, n int) (msgpackExt, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return msgpackExt{}, err
+	}
+	data, err := readMsgpackBytes(r, n)
+	return msgpackExt{Type: int8(t), Data: data}, err
+}
+
+// readMsgpackLength reads 8, 16 or 32-bit length by the size index 0, 1 or 2.
+func readMsgpackLength(r *bufio.Reader, size byte) (int, error) {
+	b, err := readMsgpackBytes(r, 1<<size)
+	if err != nil {
+		return 0, err
+	}
+	return int(msgpackUint(b)), nil
+}
+
+func readMsgpackBytes(r *bufio.Reader, n int) ([]byte, error) {
+	if n > msgpackMaxLen {
+		return nil, fmt.Errorf("%w: length %d", errMsgpackInvalid, n)
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func msgpackUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_msgpack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{name: "nil", value: nil, expected: nil},
+		{name: "true", value: true, expected: true},
+		{name: "false", value: false, expected: false},
+		{name: "fixint", value: 7, expected: int64(7)},
+		{name: "negative-fixint", value: -7, expected: int64(-7)},
+		{name: "uint8", value: uint8(200), expected: int64(200)},
+		{name: "uint16", value: 60000, expected: int64(60000)},
+		{name: "uint32", value: uint32(math.MaxUint32), expected: int64(math.MaxUint32)},
+		{name: "uint64", value: uint64(math.MaxUint64), expected: uint64(math.MaxUint64)},
+		{name: "int8", value: -100, expected: int64(-100)},
+		{name: "int16", value: -30000, expected: int64(-30000)},
+		{name: "int32", value: int32(math.MinInt32), expected: int64(math.MinInt32)},
+		{name: "int64", value: int64(math.MinInt64), expected: int64(math.MinInt64)},
+		{name: "float", value: 1.5, expected: 1.5},
+		{name: "fixstr", value: "value", expected: "value"},
+		{name: "str8", value: strings.Repeat("a", 200), expected: strings.Repeat("a", 200)},
+		{name: "str16", value: strings.Repeat("a", 70000), expected: strings.Repeat("a", 70000)},
+		{name: "duration", value: time.Second, expected: "1s"},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			appendMsgpackValue(buf, test.value)
+			value, err := decodeMsgpack(bufio.NewReader(buf))
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if !reflect.DeepEqual(value, test.expected) {
+				t.Errorf("value expected %#v, but got %#v", test.expected, value)
+			}
+		})
+	}
+}
+
+func Test_msgpack_containers(t *testing.T) {
+	t.Parallel()
+
+	tm := time.Unix(1742656070, 348957000)
+	buf := &bytes.Buffer{}
+	appendMsgpackArrayHeader(buf, 3)
+	appendMsgpackEventTime(buf, tm)
+	appendMsgpackBin(buf, []byte{1, 2, 3})
+	appendMsgpackMapHeader(buf, 20)
+	for i := 0; i < 20; i++ {
+		appendMsgpackString(buf, strings.Repeat("k", i+1))
+		appendMsgpackInt(buf, int64(i))
+	}
+
+	value, err := decodeMsgpack(bufio.NewReader(buf))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	array, ok := value.([]any)
+	if !ok || len(array) != 3 {
+		t.Fatalf("array of 3 values expected, but got %#v", value)
+	}
+	eventTime := msgpackExt{Type: 0, Data: []byte{0x67, 0xde, 0xd2, 0x46, 0x14, 0xcc, 0xa9, 0x48}}
+	if !reflect.DeepEqual(array[0], eventTime) {
+		t.Errorf("event time expected %#v, but got %#v", eventTime, array[0])
+	}
+	if !reflect.DeepEqual(array[1], []byte{1, 2, 3}) {
+		t.Errorf("bin expected %v, but got %#v", []byte{1, 2, 3}, array[1])
+	}
+	if m, ok := array[2].(map[string]any); !ok || len(m) != 20 || m["kkk"] != int64(2) {
+		t.Errorf("map of 20 values expected, but got %#v", array[2])
+	}
+}
@@ -0,0 +1,74 @@
+package log
+
+import "strings"
+
+// nameSeparator separates names of the parent and the child loggers.
+const nameSeparator = "."
+
+// Named returns a new logger with the name appended to the name of the logger, e.g. `db.pool`,
+// so the original logger is not affected and keeps its name.
+//
+// The name is printed in place of ${name} placeholder of the format. When there is a level set for the name
+// or its prefix by NameLevels, the logger uses that level, otherwise the level of the original logger.
+func Named(l Logger, name string) Logger {
+	log, ok := l.(*logger)
+	if !ok || name == "" {
+		return l
+	}
+	newName := name
+	if log.name != "" {
+		newName = log.name + nameSeparator + name
+	}
+	level := log.level
+	if nameLevel, ok := log.nameLevels.lookup(newName); ok {
+		level = nameLevel
+	}
+	return &logger{
+		level:      level,
+		format:     log.format.withName(true),
+		levelNames: log.levelNames,
+		labels:     log.labels,
+		handler:    log.handler,
+		seq:        log.seq,
+		name:       newName,
+		nameLevels: log.nameLevels,
+		exiter:     log.exiter,
+	}
+}
+
+// nameLevels are levels of the named loggers by the name prefix.
+type nameLevels map[string]*AtomicLevel
+
+// lookup finds the level by the longest prefix of the name.
+func (n nameLevels) lookup(name string) (*AtomicLevel, bool) {
+	for prefix := name; prefix != ""; {
+		if level, ok := n[prefix]; ok {
+			return level, true
+		}
+		i := strings.LastIndex(prefix, nameSeparator)
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return nil, false
+}
+
+// buildNameLevels parses levels like "db=debug,http=warn", invalid levels are skipped.
+func buildNameLevels(spec string, levelNames map[Level]string) nameLevels {
+	if spec == "" {
+		return nil
+	}
+	levels := nameLevels{}
+	for _, item := range strings.Split(spec, ",") {
+		name, levelName, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		if level, ok := parseLevelName(levelNames, strings.TrimSpace(levelName)); ok {
+			levels[name] = NewAtomicLevel(level)
+		}
+	}
+	return levels
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_buildNameLevels(t *testing.T) {
+	t.Parallel()
+
+	levelNames := copyLevelNames(nil)
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[string]Level
+	}{
+		{
+			name:     "empty",
+			spec:     "",
+			expected: map[string]Level{},
+		},
+		{
+			name:     "levels",
+			spec:     "db=debug, http = WARN ,db.pool=trace",
+			expected: map[string]Level{"db": LevelDebug, "http": LevelWarn, "db.pool": LevelTrace},
+		},
+		{
+			name:     "invalid",
+			spec:     "db,=debug,http=verbose,grpc=error",
+			expected: map[string]Level{"grpc": LevelError},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := buildNameLevels(test.spec, levelNames)
+			if len(result) != len(test.expected) {
+				t.Fatalf("expected %d levels, but got %d", len(test.expected), len(result))
+			}
+			for name, level := range test.expected {
+				if result[name] == nil || result[name].Level() != level {
+					t.Errorf("level of %q expected %d, but got %v", name, level, result[name])
+				}
+			}
+		})
+	}
+}
+
+func Test_nameLevels_lookup(t *testing.T) {
+	t.Parallel()
+
+	levels := buildNameLevels("db=debug,db.pool=trace,http=warn", copyLevelNames(nil))
+	tests := []struct {
+		name     string
+		expected Level
+		ok       bool
+	}{
+		{name: "db", expected: LevelDebug, ok: true},
+		{name: "db.pool", expected: LevelTrace, ok: true},
+		{name: "db.pool.conn", expected: LevelTrace, ok: true},
+		{name: "db.tx", expected: LevelDebug, ok: true},
+		{name: "dbx", ok: false},
+		{name: "grpc.http", ok: false},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			level, ok := levels.lookup(test.name)
+			if ok != test.ok {
+				t.Fatalf("expected found %t, but got %t", test.ok, ok)
+			}
+			if ok && level.Level() != test.expected {
+				t.Errorf("expected %d, but got %d", test.expected, level.Level())
+			}
+		})
+	}
+}
+
+func Test_Named(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	logger := New(Writer(buf), Flags(0), Format("[${level}] ${name} ${labels} ${msg}"), NameLevels("db=debug,http=error"))
+	db := Named(logger, "db")
+	pool := Named(WithLabels(db, "conn=1"), "pool")
+	http := Named(logger, "http")
+
+	logger.Debug("not logged")
+	logger.Info("root")
+	db.Debug("db")
+	pool.Debug("pool")
+	http.Warn("not logged")
+	http.Error("http")
+
+	expected := "[info] root\n[debug] db db\n[debug] db.pool conn=1 pool\n[error] http http\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+
+	handler := &testHandler{}
+	Named(Named(New(CustomHandler(handler)), "a"), "b").Info("message")
+	if len(handler.records) != 1 || handler.records[0].Name != "a.b" {
+		t.Errorf("record name expected %q, but got %#v", "a.b", handler.records)
+	}
+}
@@ -3,6 +3,7 @@ package log
 import (
 	"io"
 	"log"
+	"os"
 )
 
 type Opt func(*Opts)
@@ -29,6 +30,30 @@ func UTC() Opt {
 	}
 }
 
+// JSON prints each message as a JSON object on a separate line instead of Format.
+//
+// The message is written directly to the writer, so log.Logger flags are not printed,
+// but the time and the caller are added as JSON values according to the flags, e.g. UTC() or FileAndLine().
+//
+//	Example: {"time":"2025-03-22T15:07:50.348957+01:00","level":"info","msg":"updated","user":"1000"}
+func JSON() Opt {
+	return func(opts *Opts) {
+		opts.Encoding = EncodingJSON
+	}
+}
+
+// Logfmt prints each message as a logfmt line instead of Format.
+//
+// Level names are taken from LevelNames, labels like `key=value` are printed as key/value pairs,
+// other labels get a key with the label index. Like JSON(), log.Logger flags are not printed as a header.
+//
+//	Example: ts=2025-03-22T15:07:50.348957+01:00 level=info msg="user updated" label0=worker-1 user=1000
+func Logfmt() Opt {
+	return func(opts *Opts) {
+		opts.Encoding = EncodingLogfmt
+	}
+}
+
 // Format replaces default format of a logger, there are some predefined placeholders:
 //
 //	`${level}`: is a logger level name, e.g. DEBUG, INFO etc.;
@@ -37,6 +62,8 @@ func UTC() Opt {
 //
 //	`${msg}`: is a logger message;
 //
+//	`${name}`: is a logger name, see Named;
+//
 //	Default: `[${level}] ${labels} ${msg}\n`
 //	Example: `[debug] userId:1000 successfully logged in`
 func Format(newFormat string) Opt {
@@ -81,6 +108,28 @@ func MinLevel(level Level) Opt {
 	}
 }
 
+// NameLevels sets levels of named loggers overriding MinLevel, see Named.
+//
+// The level of a logger is taken by the longest name prefix, e.g. `db.pool` logger gets `db` level,
+// when there is no `db.pool` level. Level names are recognised the same way ByLevelName does.
+//
+//	Example: "db=debug,http=warn"
+func NameLevels(spec string) Opt {
+	return func(opts *Opts) {
+		opts.NameLevels = spec
+	}
+}
+
+// SharedLevel sets the level that can be changed at runtime,
+// all the loggers derived from the logger follow the changes of the level.
+//
+// MinLevel is ignored, when the shared level is set.
+func SharedLevel(level *AtomicLevel) Opt {
+	return func(opts *Opts) {
+		opts.AtomicLevel = level
+	}
+}
+
 // TraceLevel sets a trace log level.
 func TraceLevel() Opt {
 	return func(opts *Opts) {
@@ -144,6 +193,87 @@ func CustomLogger(logger *log.Logger) Opt {
 	}
 }
 
+// CustomHandler sets a Handler which receives all the records of the logger,
+// so encoding, flags, writer and log.Logger options are not used.
+func CustomHandler(handler Handler) Opt {
+	return func(opts *Opts) {
+		opts.Handler = handler
+	}
+}
+
+// Async passes records to the handler in background through a bounded queue, see AsyncHandler.
+//
+// Fatal waits for the queued records to be handled before the exit.
+func Async(asyncOpts AsyncOpts) Opt {
+	return func(opts *Opts) {
+		opts.Async = &asyncOpts
+	}
+}
+
+// Tee sends records to several sinks instead of a single output,
+// each sink has its own minimum level and output options, see Sink.
+//
+// The logger level is checked before the sink levels, so it should allow the most verbose sink.
+// Tee is not used, when CustomHandler is set.
+//
+//	Example:
+//
+//	log.New(log.DebugLevel(), log.Tee(
+//		log.Sink(log.LevelError, log.Writer(os.Stderr)),
+//		log.Sink(log.LevelError, log.Writer(file), log.JSON()),
+//		log.Sink(log.LevelDebug, log.CustomHandler(ring)),
+//	))
+func Tee(sinks ...SinkOpts) Opt {
+	return func(opts *Opts) {
+		opts.Sinks = append(opts.Sinks, sinks...)
+	}
+}
+
+// LevelWriter writes records of levels between from and to, inclusive, to the writer,
+// records of other levels are written to other level writers, e.g. to split the output between stdout and stderr.
+// Records of levels not covered by any level writer are written to Writer, os.Stderr by default.
+//
+// Level writers are sinks of Tee, so they can be combined with other sinks.
+//
+//	Example: log.LevelWriter(os.Stdout, log.LevelTrace, log.LevelInfo)
+func LevelWriter(w io.Writer, from, to Level) Opt {
+	if from < to {
+		from, to = to, from
+	}
+	return Tee(SinkOpts{
+		MinLevel:    from,
+		MaxLevel:    to,
+		Opts:        []Opt{Writer(w)},
+		levelWriter: true,
+	})
+}
+
+// StdStreams writes info, debug and trace records to os.Stdout, warnings and more severe records to os.Stderr.
+func StdStreams() Opt {
+	return func(opts *Opts) {
+		LevelWriter(os.Stdout, LevelTrace, LevelInfo)(opts)
+		LevelWriter(os.Stderr, LevelWarn, LevelFatal)(opts)
+	}
+}
+
+// ExitFunc replaces os.Exit called by Fatal, e.g. to test fatal paths without exiting.
+//
+// Fatal returns, when the function returns.
+func ExitFunc(exit func(code int)) Opt {
+	return func(opts *Opts) {
+		opts.ExitFunc = exit
+	}
+}
+
+// ExitCode sets the exit code of Fatal.
+//
+//	Default: 1
+func ExitCode(code int) Opt {
+	return func(opts *Opts) {
+		opts.ExitCode = code
+	}
+}
+
 // Labels sets default labels on logs.
 func Labels(labels ...string) Opt {
 	return func(opts *Opts) {
@@ -151,6 +281,13 @@ func Labels(labels ...string) Opt {
 	}
 }
 
+// Fields sets default fields on logs.
+func Fields(fields ...Field) Opt {
+	return func(opts *Opts) {
+		opts.Fields = fields
+	}
+}
+
 // LabelsFormat sets up how the labels should be printed in log inside ${labels} placeholder.
 //
 //	When there are no labels, ${labels} is replace by empty string.
@@ -174,14 +311,23 @@ func LabelsSeparator(sep string) Opt {
 
 type Opts struct {
 	Flags           int
+	Encoding        Encoding
 	Format          string
 	LevelNames      map[Level]string
 	Labels          []string
+	Fields          []Field
 	LabelsFormat    string
 	LabelsSeparator string
 	MinLevel        Level
+	AtomicLevel     *AtomicLevel
+	NameLevels      string
 	Writer          io.Writer
 	Logger          *log.Logger
+	Handler         Handler
+	Async           *AsyncOpts
+	Sinks           []SinkOpts
+	ExitFunc        func(code int)
+	ExitCode        int
 	UpperCase       bool
 }
 
@@ -202,6 +348,7 @@ func defaultOpts() *Opts {
 		LabelsSeparator: " ",
 		MinLevel:        LevelInfo,
 		Logger:          nil,
+		ExitCode:        defaultExitCode,
 	}
 }
 
@@ -209,12 +356,18 @@ func mergeOpts(base, update *Opts) *Opts {
 	if update.Flags != 0 {
 		base.Flags = update.Flags
 	}
+	if update.Encoding != EncodingText {
+		base.Encoding = update.Encoding
+	}
 	if update.Format != "" {
 		base.Format = update.Format
 	}
 	if len(update.Labels) > 0 {
 		base.Labels = update.Labels
 	}
+	if len(update.Fields) > 0 {
+		base.Fields = update.Fields
+	}
 	if update.LabelsFormat != "" {
 		base.LabelsFormat = update.LabelsFormat
 	}
@@ -231,12 +384,33 @@ func mergeOpts(base, update *Opts) *Opts {
 	if update.MinLevel != 0 {
 		base.MinLevel = normalizeLevel(update.MinLevel)
 	}
+	if update.AtomicLevel != nil {
+		base.AtomicLevel = update.AtomicLevel
+	}
+	if update.NameLevels != "" {
+		base.NameLevels = update.NameLevels
+	}
 	if update.Writer != nil {
 		base.Writer = update.Writer
 	}
 	if update.Logger != nil {
 		base.Logger = update.Logger
 	}
+	if update.Handler != nil {
+		base.Handler = update.Handler
+	}
+	if update.Async != nil {
+		base.Async = update.Async
+	}
+	if len(update.Sinks) > 0 {
+		base.Sinks = update.Sinks
+	}
+	if update.ExitFunc != nil {
+		base.ExitFunc = update.ExitFunc
+	}
+	if update.ExitCode != 0 {
+		base.ExitCode = update.ExitCode
+	}
 	base.UpperCase = update.UpperCase
 	return base
 }
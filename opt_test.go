@@ -104,6 +104,18 @@ func Test_mergeOpts(t *testing.T) {
 				MinLevel: LevelFatal,
 			},
 		},
+		{
+			name: "encoding",
+			base: Opts{
+				Encoding: EncodingText,
+			},
+			update: Opts{
+				Encoding: EncodingJSON,
+			},
+			expected: Opts{
+				Encoding: EncodingJSON,
+			},
+		},
 		{
 			name: "format",
 			base: Opts{
@@ -152,6 +164,27 @@ func Test_mergeOpts(t *testing.T) {
 				Logger: log.Default(),
 			},
 		},
+		{
+			name: "handler",
+			base: Opts{
+				Handler: nil,
+			},
+			update: Opts{
+				Handler: StdHandler(log.Default()),
+			},
+		},
+		{
+			name: "exit-code",
+			base: Opts{
+				ExitCode: defaultExitCode,
+			},
+			update: Opts{
+				ExitCode: 2,
+			},
+			expected: Opts{
+				ExitCode: 2,
+			},
+		},
 	}
 
 	for i := range tests {
@@ -165,6 +198,10 @@ func Test_mergeOpts(t *testing.T) {
 				t.Errorf("flags expected %d, but got %d", test.expected.Flags, result.Flags)
 			}
 
+			if test.expected.Encoding != result.Encoding {
+				t.Errorf("encoding expected %d, but got %d", test.expected.Encoding, result.Encoding)
+			}
+
 			if test.expected.Format != result.Format {
 				t.Errorf("format expected %d, but got %d", test.expected.Flags, result.Flags)
 			}
@@ -207,6 +244,14 @@ func Test_mergeOpts(t *testing.T) {
 				t.Errorf("logger expected %#v, but got %#v", test.expected.Logger, result.Logger)
 			}
 
+			if test.update.Handler != result.Handler {
+				t.Errorf("handler expected %#v, but got %#v", test.update.Handler, result.Handler)
+			}
+
+			if test.expected.ExitCode != result.ExitCode {
+				t.Errorf("exit code expected %d, but got %d", test.expected.ExitCode, result.ExitCode)
+			}
+
 			if test.expected.UpperCase != result.UpperCase {
 				t.Errorf("logger expected %t, but got %t", test.expected.UpperCase, result.UpperCase)
 			}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Record is a single log message, it is created once per message by the logger
+// and passed to a Handler, so the message can be inspected without parsing the text.
+//
+// Labels and Fields are shared between records and must not be modified.
+type Record struct {
+	Time      time.Time
+	Level     Level
+	LevelName string
+	Labels    []string
+	Fields    []Field
+	Message   string
+	// Name is a name of the logger, see Named.
+	Name string
+	// PC is a program counter of the caller, zero when unknown.
+	PC uintptr
+	// Seq is a sequence number of the record, it is shared by all the loggers derived from the same logger,
+	// so a gap in the numbers means that some records were lost.
+	Seq uint64
+
+	// format and labels of the logger that created the record, used by Text.
+	format *format
+	labels *labels
+}
+
+// Frame returns the caller frame, e.g. the file, the line and the function name.
+//
+// Zero frame is returned when the caller is unknown.
+func (r Record) Frame() runtime.Frame {
+	if r.PC == 0 {
+		return runtime.Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	return frame
+}
+
+// Text returns the record formatted by the logger format, e.g. `[info] user=1000 message`,
+// without log.Logger header and with a new line in the end.
+//
+// Records created outside of a logger are formatted by the default format.
+func (r Record) Text() string {
+	labels := r.labels
+	if labels == nil {
+		newLabels := buildFieldLabels(parseLabelsFormat(LabelsPlaceholder), r.Labels, r.Fields, " ")
+		labels = &newLabels
+	}
+	format := r.format
+	if format == nil {
+		newFormat := buildFormat(defaultFormat, labels.notEmpty())
+		format = &newFormat
+	}
+	value := format.withLabels(labels.notEmpty()).withName(r.Name != "").value
+	return fmt.Sprintf(value, r.LevelName, labels.formatted, r.Message, r.Name)
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_Record_Text(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "message",
+			record:   Record{LevelName: "info", Message: "message"},
+			expected: "[info] message\n",
+		},
+		{
+			name:     "labels-and-fields",
+			record:   Record{LevelName: "info", Labels: []string{"worker-1"}, Fields: []Field{Int("user", 1000)}, Message: "message"},
+			expected: "[info] worker-1 user=1000 message\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := test.record.Text()
+			if result != test.expected {
+				t.Errorf("expected %q, but got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func Test_Record(t *testing.T) {
+	t.Parallel()
+
+	handler := &testHandler{}
+	logger := New(CustomHandler(handler), Format("${level}: ${labels} ${msg}"))
+	logger.Info("first")
+	WithLabels(logger, "user=1000").Info("second")
+	func() {
+		defer func() {
+			if r := recover(); r != "panic: third\n" {
+				t.Errorf("panic expected %q, but got %q", "panic: third\n", r)
+			}
+		}()
+		logger.Panic("third")
+	}()
+
+	if len(handler.records) != 3 {
+		t.Fatalf("expected 3 records, but got %d", len(handler.records))
+	}
+	for i, r := range handler.records {
+		if r.Seq != uint64(i+1) {
+			t.Errorf("sequence number expected %d, but got %d", i+1, r.Seq)
+		}
+		if frame := r.Frame(); !strings.HasSuffix(frame.File, "record_test.go") || !strings.Contains(frame.Function, "Test_Record") {
+			t.Errorf("caller expected in Test_Record, but got %s %s", frame.File, frame.Function)
+		}
+	}
+	if r := handler.records[2]; r.Level != LevelPanic || r.LevelName != LevelNamePanic {
+		t.Errorf("panic level expected, but got %d %q", r.Level, r.LevelName)
+	}
+	if text := handler.records[1].Text(); text != "info: user=1000 second\n" {
+		t.Errorf("text expected %q, but got %q", "info: user=1000 second\n", text)
+	}
+	if frame := (Record{}).Frame(); frame.File != "" {
+		t.Errorf("empty frame expected for unknown caller, but got %#v", frame)
+	}
+}
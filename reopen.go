@@ -0,0 +1,131 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ReopenableFile is a file writer that reopens the file by its path, e.g. after logrotate moved it,
+// so it can be passed to Writer option instead of *os.File.
+//
+// Writes are blocked while the file is reopened, so no write goes to the moved file after Reopen returns.
+// ReopenableFile is safe for concurrent use.
+type ReopenableFile struct {
+	mu       sync.RWMutex
+	filename string
+	file     *os.File
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// OpenReopenableFile opens or creates the file for appending.
+func OpenReopenableFile(filename string) (*ReopenableFile, error) {
+	file, err := openAppend(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ReopenableFile{
+		filename: filename,
+		file:     file,
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Write writes to the current file.
+func (f *ReopenableFile) Write(p []byte) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	return f.file.Write(p)
+}
+
+// Reopen opens the file by its path and closes the previous one,
+// when the file can not be opened, the previous file is kept.
+func (f *ReopenableFile) Reopen() error {
+	file, err := openAppend(f.filename)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	prev := f.file
+	if prev == nil {
+		f.mu.Unlock()
+		file.Close()
+		return os.ErrClosed
+	}
+	f.file = file
+	f.mu.Unlock()
+
+	return prev.Close()
+}
+
+// ReopenOnSignal reopens the file each time one of the signals is received, SIGHUP by default.
+//
+// Signals are handled until Close is called, the method should be called once.
+func (f *ReopenableFile) ReopenOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	f.reopenOn(ch, func() { signal.Stop(ch) })
+}
+
+func (f *ReopenableFile) reopenOn(ch <-chan os.Signal, stop func()) {
+	f.stopped = make(chan struct{})
+	go func() {
+		defer close(f.stopped)
+		defer stop()
+		for {
+			select {
+			case <-ch:
+				f.Reopen()
+			case <-f.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Sync commits the file content to the storage.
+func (f *ReopenableFile) Sync() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close stops handling signals and closes the file.
+func (f *ReopenableFile) Close() error {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+		if f.stopped != nil {
+			<-f.stopped
+		}
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func openAppend(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
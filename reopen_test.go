@@ -0,0 +1,93 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_ReopenableFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	f, err := OpenReopenableFile(filename)
+	if err != nil {
+		t.Fatalf("failed to open file: %s", err)
+	}
+	logger := New(Writer(f), Flags(0))
+
+	logger.Info("before")
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatalf("failed to rename file: %s", err)
+	}
+	logger.Info("moved")
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("failed to reopen file: %s", err)
+	}
+	logger.Info("after")
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close file: %s", err)
+	}
+
+	files := readDir(t, dir)
+	if files["app.log.1"] != "[info] before\n[info] moved\n" {
+		t.Errorf("moved file expected %q, but got %q", "[info] before\n[info] moved\n", files["app.log.1"])
+	}
+	if files["app.log"] != "[info] after\n" {
+		t.Errorf("new file expected %q, but got %q", "[info] after\n", files["app.log"])
+	}
+	if _, err := f.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("write to closed file expected %v, but got %v", os.ErrClosed, err)
+	}
+}
+
+func Test_ReopenableFile_signal(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	f, err := OpenReopenableFile(filename)
+	if err != nil {
+		t.Fatalf("failed to open file: %s", err)
+	}
+	ch := make(chan os.Signal)
+	stopped := false
+	f.reopenOn(ch, func() { stopped = true })
+
+	wg := sync.WaitGroup{}
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				f.Write([]byte("line\n"))
+			}
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	os.Rename(filename, filename+".1")
+	ch <- syscall.SIGHUP
+	// the signal is received, when the next one can be sent
+	ch <- syscall.SIGHUP
+	time.Sleep(10 * time.Millisecond)
+	close(done)
+	wg.Wait()
+	f.Close()
+
+	if !stopped {
+		t.Errorf("signal handling expected to be stopped on close")
+	}
+	files := readDir(t, dir)
+	if files["app.log"] == "" {
+		t.Errorf("writes expected to the reopened file, but got %v", files)
+	}
+}
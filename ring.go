@@ -0,0 +1,63 @@
+package log
+
+import (
+	"sync"
+)
+
+const defaultRingBufferSize = 1024
+
+// RingBuffer is a Handler that keeps the last records in memory,
+// e.g. to look at debug records preceding an error, see Tee.
+type RingBuffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewRingBuffer creates a RingBuffer keeping up to size records.
+//
+//	Default size: 1024
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = defaultRingBufferSize
+	}
+	return &RingBuffer{records: make([]Record, size)}
+}
+
+// Handle keeps the record, the oldest record is replaced when the buffer is full.
+func (b *RingBuffer) Handle(r Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	b.next++
+	if b.next == len(b.records) {
+		b.next = 0
+		b.full = true
+	}
+	return nil
+}
+
+// Records returns the kept records from the oldest to the newest.
+func (b *RingBuffer) Records() []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	records := make([]Record, 0, len(b.records))
+	records = append(records, b.records[b.next:]...)
+	return append(records, b.records[:b.next]...)
+}
+
+// Reset removes all the kept records.
+func (b *RingBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.records)
+	b.next = 0
+	b.full = false
+}
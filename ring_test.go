@@ -0,0 +1,65 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_RingBuffer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		size     int
+		messages []string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			size:     3,
+			messages: nil,
+			expected: []string{},
+		},
+		{
+			name:     "not-full",
+			size:     3,
+			messages: []string{"1", "2"},
+			expected: []string{"1", "2"},
+		},
+		{
+			name:     "full",
+			size:     3,
+			messages: []string{"1", "2", "3"},
+			expected: []string{"1", "2", "3"},
+		},
+		{
+			name:     "overwritten",
+			size:     3,
+			messages: []string{"1", "2", "3", "4", "5"},
+			expected: []string{"3", "4", "5"},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ring := NewRingBuffer(test.size)
+			for _, msg := range test.messages {
+				_ = ring.Handle(Record{Message: msg})
+			}
+			messages := []string{}
+			for _, r := range ring.Records() {
+				messages = append(messages, r.Message)
+			}
+			if !reflect.DeepEqual(messages, test.expected) {
+				t.Errorf("messages expected %v, but got %v", test.expected, messages)
+			}
+
+			ring.Reset()
+			if records := ring.Records(); len(records) != 0 {
+				t.Errorf("no records expected after reset, but got %d", len(records))
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// RotateOpts configures a RotatingFile, zero values turn the features off.
+type RotateOpts struct {
+	// MaxSize is a size in bytes, when the file is rotated before it gets bigger.
+	MaxSize int64
+	// Daily rotates the file on the first write of a new day.
+	Daily bool
+	// UTC uses UTC time for day boundaries and backup names instead of the local time.
+	UTC bool
+	// MaxAge removes backups which are older than the age.
+	MaxAge time.Duration
+	// MaxBackups removes the oldest backups, when there are more backups than the number.
+	MaxBackups int
+	// Compress compresses backups by gzip.
+	Compress bool
+}
+
+// RotatingFile is a file writer that rotates the file by size and/or daily,
+// so it can be passed to Writer option instead of *os.File.
+//
+// The rotated file is renamed to a backup with a timestamp, e.g. `app.log` -> `app-2025-03-22T15-07-50.348.log`,
+// and optionally compressed, old backups are removed in background.
+// RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	mu           sync.Mutex
+	filename     string
+	opts         RotateOpts
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+
+	millMu sync.Mutex
+	millWg sync.WaitGroup
+
+	now func() time.Time
+}
+
+// OpenRotatingFile opens or creates the file for appending.
+func OpenRotatingFile(filename string, opts RotateOpts) (*RotatingFile, error) {
+	f := &RotatingFile{
+		filename: filename,
+		opts:     opts,
+		now:      time.Now,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes to the file, rotating it first if the size limit is reached or a new day began.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file regardless of the limits.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Sync commits the file content to the storage.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for the backups to be compressed and removed.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.millWg.Wait()
+	return err
+}
+
+func (f *RotatingFile) needsRotation(n int) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(n) > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.Daily && !f.currentTime().Before(f.nextRotation)
+}
+
+func (f *RotatingFile) open() error {
+	file, err := openAppend(f.filename)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	opened := f.currentTime()
+	if f.size > 0 {
+		opened = f.toZone(info.ModTime())
+	}
+	year, month, day := opened.Date()
+	f.nextRotation = time.Date(year, month, day+1, 0, 0, 0, 0, opened.Location())
+	return nil
+}
+
+// rotate renames the open file to a backup and opens a new file, the current file is kept,
+// when the rotation fails, so the next write retries it.
+func (f *RotatingFile) rotate() error {
+	backup := f.backupName(f.currentTime())
+	renamed := true
+	if err := os.Rename(f.filename, backup); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// the file was removed, so a new one is created only
+		renamed = false
+	}
+	current := f.file
+	if err := f.open(); err != nil {
+		if renamed {
+			// the backup is renamed back, so it is rotated by the next attempt
+			os.Rename(backup, f.filename)
+		}
+		return err
+	}
+	current.Close()
+
+	if renamed {
+		f.millWg.Add(1)
+		go f.mill(backup)
+	}
+	return nil
+}
+
+// mill compresses the backup and removes old backups.
+func (f *RotatingFile) mill(backup string) {
+	defer f.millWg.Done()
+
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	if f.opts.Compress {
+		compressFile(backup)
+	}
+	f.removeBackups()
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.backupParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(name + compressSuffix); errors.Is(err, os.ErrNotExist) {
+				return name
+			}
+		}
+		// the backup was created within the same millisecond
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func (f *RotatingFile) backupParts() (dir string, prefix string, ext string) {
+	dir = filepath.Dir(f.filename)
+	base := filepath.Base(f.filename)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+type backup struct {
+	name string
+	time time.Time
+}
+
+func (f *RotatingFile) removeBackups() {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxAge <= 0 {
+		return
+	}
+	backups := f.backups()
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+
+	cutoff := time.Time{}
+	if f.opts.MaxAge > 0 {
+		cutoff = f.currentTime().Add(-f.opts.MaxAge)
+	}
+	for i, b := range backups {
+		if (f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups) || (!cutoff.IsZero() && b.time.Before(cutoff)) {
+			os.Remove(b.name)
+		}
+	}
+}
+
+func (f *RotatingFile) backups() []backup {
+	dir, prefix, ext := f.backupParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	backups := make([]backup, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix)
+		if !strings.HasSuffix(timestamp, ext) {
+			continue
+		}
+		location := time.Local
+		if f.opts.UTC {
+			location = time.UTC
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(timestamp, ext), location)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: filepath.Join(dir, name), time: t})
+	}
+	return backups
+}
+
+func (f *RotatingFile) currentTime() time.Time {
+	return f.toZone(f.now())
+}
+
+func (f *RotatingFile) toZone(t time.Time) time.Time {
+	if f.opts.UTC {
+		return t.UTC()
+	}
+	return t.Local()
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + compressSuffix)
+		return err
+	}
+	return os.Remove(name)
+}
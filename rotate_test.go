@@ -0,0 +1,270 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock returns the time which is moved manually.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func openTestRotatingFile(t *testing.T, opts RotateOpts, clock *testClock) (*RotatingFile, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(filepath.Join(dir, "app.log"), opts)
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %s", err)
+	}
+	if clock != nil {
+		// reopens the file to use the clock for the next rotation time
+		f.file.Close()
+		f.now = clock.Now
+		if err := f.open(); err != nil {
+			t.Fatalf("failed to reopen rotating file: %s", err)
+		}
+	}
+	return f, dir
+}
+
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %s", err)
+	}
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to read file: %s", err)
+		}
+		if strings.HasSuffix(entry.Name(), compressSuffix) {
+			gz, err := gzip.NewReader(bytes.NewReader(content))
+			if err != nil {
+				t.Fatalf("failed to read gzip file: %s", err)
+			}
+			content, _ = io.ReadAll(gz)
+		}
+		files[entry.Name()] = string(content)
+	}
+	return files
+}
+
+func Test_RotatingFile_size(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2025, 3, 22, 15, 7, 50, 0, time.UTC)}
+	f, dir := openTestRotatingFile(t, RotateOpts{MaxSize: 10, UTC: true}, clock)
+	for _, line := range []string{"first\n", "second\n", "3\n", "too long line\n"} {
+		clock.Add(time.Second)
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("failed to write: %s", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+
+	expected := map[string]string{
+		"app-2025-03-22T15-07-52.000.log": "first\n",
+		"app-2025-03-22T15-07-54.000.log": "second\n3\n",
+		"app.log":                         "too long line\n",
+	}
+	files := readDir(t, dir)
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, but got %v", expected, files)
+	}
+	for name, content := range expected {
+		if files[name] != content {
+			t.Errorf("file %s expected %q, but got %q", name, content, files[name])
+		}
+	}
+}
+
+func Test_RotatingFile_daily(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2025, 3, 22, 23, 59, 0, 0, time.UTC)}
+	f, dir := openTestRotatingFile(t, RotateOpts{Daily: true, UTC: true}, clock)
+
+	f.Write([]byte("first\n"))
+	f.Write([]byte("second\n"))
+	clock.Add(70 * time.Second)
+	f.Write([]byte("third\n"))
+	f.Write([]byte("fourth\n"))
+	f.Close()
+
+	expected := map[string]string{
+		"app-2025-03-23T00-00-10.000.log": "first\nsecond\n",
+		"app.log":                         "third\nfourth\n",
+	}
+	files := readDir(t, dir)
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, but got %v", expected, files)
+	}
+	for name, content := range expected {
+		if files[name] != content {
+			t.Errorf("file %s expected %q, but got %q", name, content, files[name])
+		}
+	}
+}
+
+func Test_RotatingFile_retention(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     RotateOpts
+		expected []string
+	}{
+		{
+			name: "max-backups-compressed",
+			opts: RotateOpts{UTC: true, MaxBackups: 2, Compress: true},
+			expected: []string{
+				"app-2025-03-22T15-07-53.000.log.gz",
+				"app-2025-03-22T15-07-54.000.log.gz",
+				"app.log",
+			},
+		},
+		{
+			name: "max-age",
+			opts: RotateOpts{UTC: true, MaxAge: 2 * time.Second},
+			expected: []string{
+				"app-2025-03-22T15-07-52.000.log",
+				"app-2025-03-22T15-07-53.000.log",
+				"app-2025-03-22T15-07-54.000.log",
+				"app.log",
+			},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &testClock{now: time.Date(2025, 3, 22, 15, 7, 50, 0, time.UTC)}
+			f, dir := openTestRotatingFile(t, test.opts, clock)
+			for i := 0; i < 4; i++ {
+				clock.Add(time.Second)
+				f.Write([]byte("line\n"))
+				// mill runs in background, so the backups are removed one by one
+				f.millWg.Wait()
+				if err := f.Rotate(); err != nil {
+					t.Fatalf("failed to rotate: %s", err)
+				}
+			}
+			f.Close()
+
+			files := readDir(t, dir)
+			names := make([]string, 0, len(files))
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("expected files %v, but got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func Test_RotatingFile_failedRotation(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2025, 3, 22, 15, 7, 50, 0, time.UTC)}
+	f, dir := openTestRotatingFile(t, RotateOpts{MaxSize: 10, UTC: true}, clock)
+	defer f.Close()
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+
+	// the new file can not be created, while the directory is removed
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("failed to remove dir: %s", err)
+	}
+	clock.Add(time.Second)
+	if _, err := f.Write([]byte("second\n")); err == nil {
+		t.Fatal("failing write expected, while the directory is removed")
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("failed to create dir: %s", err)
+	}
+	for _, line := range []string{"third\n", "4\n"} {
+		clock.Add(time.Second)
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("failed to write after the directory is recreated: %s", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+
+	expected := map[string]string{
+		"app.log": "third\n4\n",
+	}
+	files := readDir(t, dir)
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, but got %v", expected, files)
+	}
+	for name, content := range expected {
+		if files[name] != content {
+			t.Errorf("file %s expected %q, but got %q", name, content, files[name])
+		}
+	}
+}
+
+func Test_RotatingFile_concurrent(t *testing.T) {
+	t.Parallel()
+
+	f, dir := openTestRotatingFile(t, RotateOpts{MaxSize: 1000}, nil)
+	logger := New(Writer(f), Flags(0))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	wg.Wait()
+	f.Close()
+
+	lines := 0
+	for _, content := range readDir(t, dir) {
+		lines += strings.Count(content, "[info] message\n")
+	}
+	if lines != 1000 {
+		t.Errorf("expected %d lines, but got %d", 1000, lines)
+	}
+}
@@ -0,0 +1,318 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// slog levels of the Logger levels, which are not defined by log/slog package.
+const (
+	SlogLevelTrace slog.Level = slog.LevelDebug - 4
+	SlogLevelPanic slog.Level = slog.LevelError + 4
+	SlogLevelFatal slog.Level = slog.LevelError + 8
+)
+
+// SlogHandler is a slog.Handler that writes slog records to a Logger.
+//
+// Attributes and groups are added to the Logger as fields, group names are used as key prefixes,
+// e.g. `request.id=1`. Records with SlogLevelPanic and SlogLevelFatal levels are only logged,
+// they neither panic nor exit.
+type SlogHandler struct {
+	logger Logger
+	prefix string
+}
+
+// NewSlogHandler creates a slog.Handler that writes records to the logger,
+// so slog.Logger and Logger can share the same configuration.
+//
+//	Example: slog.New(log.NewSlogHandler(logger)).Info("updated", "user", 1000)
+func NewSlogHandler(l Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// Enabled reports whether the logger level allows the slog level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	log, ok := h.logger.(*logger)
+	if !ok {
+		return true
+	}
+	return log.level.Level() >= levelFromSlog(level)
+}
+
+// Handle writes the record to the logger.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	level := levelFromSlog(r.Level)
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	log, ok := h.logger.(*logger)
+	if !ok {
+		kv := make([]any, len(fields))
+		for i := range fields {
+			kv[i] = fields[i]
+		}
+		h.logger.Logw(level, r.Message, kv...)
+		return nil
+	}
+	if log.level.Level() < level {
+		return nil
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	log.handle(level, log.labels.addFields(fields...), r.Message, t, r.PC)
+	return nil
+}
+
+// WithAttrs returns a handler with attributes added to the logger as fields.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make([]Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, attr)
+	}
+	return &SlogHandler{
+		logger: WithFields(h.logger, fields...),
+		prefix: h.prefix,
+	}
+}
+
+// WithGroup returns a handler that prefixes keys of the following attributes with the group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger: h.logger,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+func appendSlogAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, groupAttr)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
+
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	case level < SlogLevelPanic:
+		return LevelError
+	case level < SlogLevelFatal:
+		return LevelPanic
+	}
+	return LevelFatal
+}
+
+// FromSlogHandler creates a Logger that writes messages to the slog.Handler,
+// e.g. slog.NewJSONHandler, so the Logger interface can be used with any slog backend.
+//
+// Only the level, the shared level, labels, fields and exit options are used, labels are added as attributes
+// the same way as logfmt does, e.g. `user=1000` label becomes `user` attribute.
+func FromSlogHandler(h slog.Handler, opts ...Opt) Logger {
+	options := defaultOpts()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return newSlogLogger(h, buildLevel(options), copyLabels(options.Labels), copyFields(options.Fields), buildExiter(options))
+}
+
+type slogLogger struct {
+	base    slog.Handler
+	handler slog.Handler
+	level   *AtomicLevel
+	labels  []string
+	fields  []Field
+	exiter  *exiter
+}
+
+func newSlogLogger(base slog.Handler, level *AtomicLevel, labels []string, fields []Field, exiter *exiter) *slogLogger {
+	handler := base
+	attrs := make([]slog.Attr, 0, len(labels)+len(fields))
+	for _, field := range labelFields(labels) {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	if len(attrs) > 0 {
+		handler = base.WithAttrs(attrs)
+	}
+	return &slogLogger{
+		base:    base,
+		handler: handler,
+		level:   level,
+		labels:  labels,
+		fields:  fields,
+		exiter:  exiter,
+	}
+}
+
+func (l *slogLogger) Log(lvl Level, v ...any)               { l.log(normalizeLevel(lvl), v...) }
+func (l *slogLogger) Logf(lvl Level, f string, v ...any)    { l.logf(normalizeLevel(lvl), f, v...) }
+func (l *slogLogger) Logw(lvl Level, msg string, kv ...any) { l.logw(normalizeLevel(lvl), msg, kv...) }
+func (l *slogLogger) Trace(v ...any)                        { l.log(LevelTrace, v...) }
+func (l *slogLogger) Tracef(f string, v ...any)             { l.logf(LevelTrace, f, v...) }
+func (l *slogLogger) Tracew(msg string, kv ...any)          { l.logw(LevelTrace, msg, kv...) }
+func (l *slogLogger) Debug(v ...any)                        { l.log(LevelDebug, v...) }
+func (l *slogLogger) Debugf(f string, v ...any)             { l.logf(LevelDebug, f, v...) }
+func (l *slogLogger) Debugw(msg string, kv ...any)          { l.logw(LevelDebug, msg, kv...) }
+func (l *slogLogger) Info(v ...any)                         { l.log(LevelInfo, v...) }
+func (l *slogLogger) Infof(f string, v ...any)              { l.logf(LevelInfo, f, v...) }
+func (l *slogLogger) Infow(msg string, kv ...any)           { l.logw(LevelInfo, msg, kv...) }
+func (l *slogLogger) Notice(v ...any)                       { l.log(LevelInfo, v...) }
+func (l *slogLogger) Noticef(f string, v ...any)            { l.logf(LevelInfo, f, v...) }
+func (l *slogLogger) Noticew(msg string, kv ...any)         { l.logw(LevelInfo, msg, kv...) }
+func (l *slogLogger) Warn(v ...any)                         { l.log(LevelWarn, v...) }
+func (l *slogLogger) Warnf(f string, v ...any)              { l.logf(LevelWarn, f, v...) }
+func (l *slogLogger) Warnw(msg string, kv ...any)           { l.logw(LevelWarn, msg, kv...) }
+func (l *slogLogger) Error(v ...any)                        { l.log(LevelError, v...) }
+func (l *slogLogger) Errorf(f string, v ...any)             { l.logf(LevelError, f, v...) }
+func (l *slogLogger) Errorw(msg string, kv ...any)          { l.logw(LevelError, msg, kv...) }
+func (l *slogLogger) Panic(v ...any)                        { l.panic(fmt.Sprint(v...), nil) }
+func (l *slogLogger) Panicf(f string, v ...any)             { l.panic(fmt.Sprintf(f, v...), nil) }
+func (l *slogLogger) Panicw(msg string, kv ...any)          { l.panic(msg, kv) }
+func (l *slogLogger) Fatal(v ...any)                        { l.fatal(fmt.Sprint(v...), nil) }
+func (l *slogLogger) Fatalf(f string, v ...any)             { l.fatal(fmt.Sprintf(f, v...), nil) }
+func (l *slogLogger) Fatalw(msg string, kv ...any)          { l.fatal(msg, kv) }
+
+func (l *slogLogger) enabled(level Level) bool {
+	return l.level.Level() >= level && l.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+func (l *slogLogger) log(level Level, v ...any) {
+	if len(v) == 0 || !l.enabled(level) {
+		return
+	}
+
+	l.output(level, fmt.Sprint(v...), nil)
+}
+
+func (l *slogLogger) logf(level Level, f string, v ...any) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.output(level, fmt.Sprintf(f, v...), nil)
+}
+
+func (l *slogLogger) logw(level Level, msg string, kv ...any) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.output(level, msg, kv)
+}
+
+func (l *slogLogger) panic(msg string, kv []any) {
+	if l.level.Level() < LevelPanic {
+		return
+	}
+
+	l.output(LevelPanic, msg, kv)
+	panic(msg)
+}
+
+func (l *slogLogger) fatal(msg string, kv []any) {
+	if l.level.Level() < LevelFatal {
+		return
+	}
+
+	l.output(LevelFatal, msg, kv)
+	l.exiter.fatal(nil)
+}
+
+// output passes the message to the handler,
+// the caller is counted from the public Logger methods, e.g. Info -> log -> output.
+func (l *slogLogger) output(level Level, msg string, kv []any) {
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:])
+	r := slog.NewRecord(time.Now(), slogLevel(level), msg, pcs[0])
+	for _, field := range fieldsFromKeyValues(kv) {
+		r.AddAttrs(slog.Any(field.Key, field.Value))
+	}
+	l.handler.Handle(context.Background(), r)
+}
+
+func (l *slogLogger) withLabels(labels []string) *slogLogger {
+	newLabels := joinLabels(l.labels, labels)
+	if len(newLabels) == len(l.labels) {
+		return l
+	}
+	return newSlogLogger(l.base, l.level, newLabels, l.fields, l.exiter)
+}
+
+func (l *slogLogger) withFields(fields []Field) *slogLogger {
+	newFields := joinFields(l.fields, fields)
+	if len(newFields) == len(l.fields) {
+		return l
+	}
+	return newSlogLogger(l.base, l.level, l.labels, newFields, l.exiter)
+}
+
+func (l *slogLogger) withLevel(level Level) *slogLogger {
+	return &slogLogger{
+		base:    l.base,
+		handler: l.handler,
+		level:   NewAtomicLevel(level),
+		labels:  l.labels,
+		fields:  l.fields,
+		exiter:  l.exiter,
+	}
+}
+
+func (l *slogLogger) clearLabels() *slogLogger {
+	return &slogLogger{
+		base:    l.base,
+		handler: l.base,
+		level:   l.level,
+		exiter:  l.exiter,
+	}
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case LevelTrace:
+		return SlogLevelTrace
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelPanic:
+		return SlogLevelPanic
+	}
+	return SlogLevelFatal
+}
@@ -0,0 +1,199 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_levelFromSlog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		level    slog.Level
+		expected Level
+	}{
+		{
+			name:     "trace",
+			level:    SlogLevelTrace,
+			expected: LevelTrace,
+		},
+		{
+			name:     "debug",
+			level:    slog.LevelDebug,
+			expected: LevelDebug,
+		},
+		{
+			name:     "between-debug-and-info",
+			level:    slog.LevelDebug + 2,
+			expected: LevelDebug,
+		},
+		{
+			name:     "info",
+			level:    slog.LevelInfo,
+			expected: LevelInfo,
+		},
+		{
+			name:     "warn",
+			level:    slog.LevelWarn,
+			expected: LevelWarn,
+		},
+		{
+			name:     "error",
+			level:    slog.LevelError,
+			expected: LevelError,
+		},
+		{
+			name:     "panic",
+			level:    SlogLevelPanic,
+			expected: LevelPanic,
+		},
+		{
+			name:     "fatal",
+			level:    SlogLevelFatal,
+			expected: LevelFatal,
+		},
+		{
+			name:     "bigger-than-fatal",
+			level:    100,
+			expected: LevelFatal,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := levelFromSlog(test.level)
+			if result != test.expected {
+				t.Errorf("expected %d, but got %d", test.expected, result)
+			}
+		})
+	}
+}
+
+func Test_SlogHandler(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	handler := NewSlogHandler(New(Writer(buf), Flags(0), Labels("worker-1")))
+	logger := slog.New(handler)
+
+	logger.Debug("not logged")
+	logger.With("user", 1000).WithGroup("request").Info("updated", "id", "a1", slog.Group("db", "rows", 2), slog.Group("empty"))
+	logger.Warn("slow", slog.Group("", "elapsed", "1s"))
+
+	expected := "[info] worker-1 user=1000 request.id=a1 request.db.rows=2 updated\n[warn] worker-1 elapsed=1s slow\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+
+	if handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("debug level is expected to be disabled")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("info level is expected to be enabled")
+	}
+}
+
+func Test_SlogHandler_caller(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Opt
+	}{
+		{
+			name: "text",
+			opts: []Opt{Flags(log.Lshortfile)},
+		},
+		{
+			name: "json",
+			opts: []Opt{Flags(log.Lshortfile), JSON()},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			slog.New(NewSlogHandler(New(append(test.opts, Writer(buf))...))).Info("message")
+			if !strings.Contains(buf.String(), "slog_test.go:") {
+				t.Errorf("caller expected in slog_test.go, but got %q", buf.String())
+			}
+		})
+	}
+}
+
+func Test_FromSlogHandler(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: SlogLevelTrace,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := FromSlogHandler(handler, Labels("worker-1"))
+
+	logger.Debug("not logged")
+	logger = WithLabels(logger, "user=1000")
+	logger.Infow("updated", "attempt", 3)
+	WithLevel(logger, LevelTrace).Tracef("traced %d", 1)
+	WithFields(ClearLabels(logger), String("name", "john")).Error("failed")
+
+	expected := "level=INFO msg=updated label0=worker-1 user=1000 attempt=3\n" +
+		"level=DEBUG-4 msg=\"traced 1\" label0=worker-1 user=1000\n" +
+		"level=ERROR msg=failed name=john\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+}
+
+func Test_FromSlogHandler_options(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: SlogLevelTrace,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	level := NewAtomicLevel(LevelInfo)
+	logger := FromSlogHandler(handler, SharedLevel(level), Labels("cpu=100%"))
+
+	logger.Debug("not logged")
+	level.SetLevel(LevelDebug)
+	logger.Debug("logged")
+	// the format is not used by slog, but the logger is kept
+	WithFormat(logger, "${msg}").Info("formatted")
+
+	expected := "level=DEBUG msg=logged cpu=100%\n" +
+		"level=INFO msg=formatted cpu=100%\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, buf.String())
+	}
+}
+
+func Test_FromSlogHandler_caller(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	FromSlogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{AddSource: true})).Info("message")
+	if !strings.Contains(buf.String(), "slog_test.go") {
+		t.Errorf("caller expected in slog_test.go, but got %q", buf.String())
+	}
+}
@@ -0,0 +1,431 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// SyslogStructuredDataID is the default SD-ID of labels and fields,
+	// 32473 is the private enterprise number reserved for documentation.
+	SyslogStructuredDataID = "labels@32473"
+
+	syslogTimeFormat     = "2006-01-02T15:04:05.000000Z07:00"
+	syslogNilValue       = "-"
+	defaultSyslogTimeout = 5 * time.Second
+
+	// rfc3164MaxLen is the maximum length of RFC 3164 message, longer messages are truncated.
+	rfc3164MaxLen = 1024
+)
+
+// SyslogFormat is a format of syslog messages.
+type SyslogFormat int
+
+const (
+	// SyslogRFC5424 formats messages per RFC 5424 with structured data.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 formats messages per legacy BSD syslog RFC 3164,
+	// labels and fields are appended to the message.
+	SyslogRFC3164
+)
+
+// Facility is a syslog facility of the messages.
+type Facility int
+
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+)
+
+const (
+	FacilityLocal0 Facility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// syslogSocketPaths are paths of the local syslog socket on different systems.
+var syslogSocketPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogOpts configures a SyslogHandler, zero values are replaced by defaults.
+type SyslogOpts struct {
+	// Format of the messages.
+	//
+	//	Default: SyslogRFC5424
+	Format SyslogFormat
+	// Facility of the messages.
+	//
+	//	Default: FacilityUser
+	Facility Facility
+	// Hostname of the messages.
+	//
+	//	Default: os.Hostname()
+	Hostname string
+	// AppName of the messages.
+	//
+	//	Default: the executable name
+	AppName string
+	// ProcID of the messages.
+	//
+	//	Default: the process id
+	ProcID string
+	// Tag of RFC 3164 messages, it is followed by ProcID.
+	//
+	//	Default: AppName
+	Tag string
+	// StructuredDataID is SD-ID of the element with labels and fields.
+	//
+	//	Default: SyslogStructuredDataID
+	StructuredDataID string
+	// Timeout limits the time of connecting and writing a message.
+	//
+	//	Default: 5s
+	Timeout time.Duration
+}
+
+// SyslogHandler is a Handler that sends records to a syslog server framed per RFC 5424:
+//
+//	<11>1 2025-03-22T15:07:50.348957+01:00 host app 42 db [labels@32473 user="1000"] failed
+//
+// The logger name is sent as MSGID, labels and fields are sent as structured data.
+// Legacy RFC 3164 messages are truncated to 1024 bytes, see SyslogRFC3164:
+//
+//	<11>Mar 22 15:07:50 host app[42]: failed user=1000
+//
+// The connection is reestablished, when sending fails. SyslogHandler is safe for concurrent use.
+type SyslogHandler struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	network string
+	addr    string
+	opts    SyslogOpts
+	conn    net.Conn
+	closed  bool
+}
+
+// DialSyslog connects to the syslog server, the network is "udp", "tcp" or "unix" and "unixgram" for sockets.
+//
+// RFC 5424 messages are sent over TCP with octet-counted framing, RFC 3164 messages and messages
+// over unix stream sockets are terminated by a new line, over other networks one message is sent per datagram.
+// The local syslog socket, e.g. `/dev/log`, is used, when the network and the address are empty.
+func DialSyslog(network, addr string, opts SyslogOpts) (*SyslogHandler, error) {
+	if opts.Facility == 0 {
+		opts.Facility = FacilityUser
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.ProcID == "" {
+		opts.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if opts.Tag == "" {
+		opts.Tag = opts.AppName
+	}
+	if opts.StructuredDataID == "" {
+		opts.StructuredDataID = SyslogStructuredDataID
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultSyslogTimeout
+	}
+
+	h := &SyslogHandler{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+	}
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Handle sends the record, the connection is reestablished once, when sending fails.
+func (h *SyslogHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+	h.buf.Reset()
+	h.encode(&r)
+
+	if h.conn != nil {
+		if err := h.write(); err == nil {
+			return nil
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	if err := h.connect(); err != nil {
+		return err
+	}
+	return h.write()
+}
+
+// Close closes the connection.
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+func (h *SyslogHandler) connect() error {
+	if h.network != "" || h.addr != "" {
+		conn, err := net.DialTimeout(h.network, h.addr, h.opts.Timeout)
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+		return nil
+	}
+
+	var errs []error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogSocketPaths {
+			conn, err := net.DialTimeout(network, path, h.opts.Timeout)
+			if err == nil {
+				h.conn = conn
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *SyslogHandler) write() error {
+	if err := h.conn.SetWriteDeadline(time.Now().Add(h.opts.Timeout)); err != nil {
+		return err
+	}
+	msg := h.buf.Bytes()
+	switch h.conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6":
+		if h.opts.Format == SyslogRFC3164 {
+			msg = append(msg, '\n')
+			break
+		}
+		// octet-counted framing, RFC 6587
+		msg = append(strconv.AppendInt(nil, int64(len(msg)), 10), ' ')
+		msg = append(msg, h.buf.Bytes()...)
+	case "unix":
+		// local daemons split a stream by new lines
+		msg = append(msg, '\n')
+	}
+	_, err := h.conn.Write(msg)
+	return err
+}
+
+// encode writes the record in the handler format.
+func (h *SyslogHandler) encode(r *Record) {
+	h.buf.WriteByte('<')
+	h.buf.WriteString(strconv.Itoa(int(h.opts.Facility)*8 + syslogSeverity(r.Level)))
+	h.buf.WriteByte('>')
+	if h.opts.Format == SyslogRFC3164 {
+		h.encodeRFC3164(r)
+		return
+	}
+	h.buf.WriteString("1 ")
+	if r.Time.IsZero() {
+		h.buf.WriteString(syslogNilValue)
+	} else {
+		h.buf.WriteString(r.Time.Format(syslogTimeFormat))
+	}
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(syslogHeaderValue(h.opts.Hostname, 255))
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(syslogHeaderValue(h.opts.AppName, 48))
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(syslogHeaderValue(h.opts.ProcID, 128))
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(syslogHeaderValue(r.Name, 32))
+	h.buf.WriteByte(' ')
+	appendSyslogStructuredData(&h.buf, h.opts.StructuredDataID, r)
+	if r.Message != "" {
+		h.buf.WriteByte(' ')
+		h.buf.WriteString(strings.TrimRight(r.Message, "\n"))
+	}
+}
+
+// encodeRFC3164 writes the record as RFC 3164 message after the priority.
+func (h *SyslogHandler) encodeRFC3164(r *Record) {
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	h.buf.WriteString(t.Format(time.Stamp))
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(syslogHeaderValue(h.opts.Hostname, 255))
+	h.buf.WriteByte(' ')
+	h.buf.WriteString(rfc3164Tag(h.opts.Tag))
+	if h.opts.ProcID != "" {
+		h.buf.WriteByte('[')
+		h.buf.WriteString(syslogHeaderValue(h.opts.ProcID, 128))
+		h.buf.WriteByte(']')
+	}
+	h.buf.WriteString(": ")
+	h.buf.WriteString(strings.TrimRight(r.Message, "\n"))
+	if len(r.Labels) > 0 || len(r.Fields) > 0 {
+		h.buf.WriteByte(' ')
+		h.buf.WriteString(joinLabelsText(r.Labels, r.Fields, " "))
+	}
+	// new lines would split the message
+	msg := h.buf.Bytes()
+	for i := range msg {
+		if msg[i] == '\n' {
+			msg[i] = ' '
+		}
+	}
+	h.buf.Truncate(truncateUTF8(msg, rfc3164MaxLen))
+}
+
+// rfc3164Tag keeps alphanumeric characters, dashes, dots and underscores of the tag, up to 32 characters.
+func rfc3164Tag(tag string) string {
+	tag = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_' || r == '/' {
+			return r
+		}
+		return '_'
+	}, tag)
+	if len(tag) > 32 {
+		tag = tag[:32]
+	}
+	if tag == "" {
+		return syslogNilValue
+	}
+	return tag
+}
+
+// truncateUTF8 returns the length of b truncated to maxLen bytes without splitting a UTF-8 character.
+func truncateUTF8(b []byte, maxLen int) int {
+	if len(b) <= maxLen {
+		return len(b)
+	}
+	n := maxLen
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return n
+}
+
+// syslogSeverity maps the level to the syslog severity.
+func syslogSeverity(level Level) int {
+	switch level {
+	case LevelFatal:
+		return 0 // emergency
+	case LevelPanic:
+		return 2 // critical
+	case LevelError:
+		return 3 // error
+	case LevelWarn:
+		return 4 // warning
+	case LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogHeaderValue replaces characters which are not printable ASCII with underscores
+// and truncates the value, the nil value is used for empty values.
+func syslogHeaderValue(value string, maxLen int) string {
+	if value == "" {
+		return syslogNilValue
+	}
+	value = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
+
+// appendSyslogStructuredData writes labels and fields as a structured data element:
+//
+//	[labels@32473 user="1000" label1="primary"]
+func appendSyslogStructuredData(buf *bytes.Buffer, id string, r *Record) {
+	fields := append(labelFields(r.Labels), r.Fields...)
+	if len(fields) == 0 {
+		buf.WriteString(syslogNilValue)
+		return
+	}
+	buf.WriteByte('[')
+	buf.WriteString(id)
+	for _, field := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(syslogParamName(field.Key))
+		buf.WriteString(`="`)
+		appendSyslogParamValue(buf, formatFieldValue(field.Value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+}
+
+// syslogParamName replaces characters which are not allowed in PARAM-NAME with underscores.
+func syslogParamName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// appendSyslogParamValue writes PARAM-VALUE escaping `"`, `\` and `]`.
+func appendSyslogParamValue(buf *bytes.Buffer, value string) {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSyslogOpts = SyslogOpts{
+	Facility: FacilityLocal0,
+	Hostname: "host",
+	AppName:  "app",
+	ProcID:   "42",
+}
+
+func Test_SyslogHandler_encode(t *testing.T) {
+	t.Parallel()
+
+	tm := time.Date(2025, 3, 22, 15, 7, 50, 348957000, time.UTC)
+	tests := []struct {
+		name     string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "info",
+			record:   Record{Time: tm, Level: LevelInfo, Message: "started"},
+			expected: "<134>1 2025-03-22T15:07:50.348957Z host app 42 - - started",
+		},
+		{
+			name:     "no-time",
+			record:   Record{Level: LevelDebug, Message: "checked\n"},
+			expected: "<135>1 - host app 42 - - checked",
+		},
+		{
+			name:     "name",
+			record:   Record{Time: tm, Level: LevelWarn, Message: "slow", Name: "db pool"},
+			expected: "<132>1 2025-03-22T15:07:50.348957Z host app 42 db_pool - slow",
+		},
+		{
+			name: "structured-data",
+			record: Record{
+				Time: tm, Level: LevelError, Message: "failed",
+				Labels: []string{"user=1000", "worker"},
+				Fields: []Field{String("query", `select "x]\"`), Int("my key", 1)},
+			},
+			expected: `<131>1 2025-03-22T15:07:50.348957Z host app 42 - [labels@32473 user="1000" label1="worker" query="select \"x\]\\\"" my_key="1"] failed`,
+		},
+		{
+			name:     "fatal",
+			record:   Record{Time: tm, Level: LevelFatal},
+			expected: "<128>1 2025-03-22T15:07:50.348957Z host app 42 - -",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testSyslogOpts
+			opts.StructuredDataID = SyslogStructuredDataID
+			h := &SyslogHandler{opts: opts}
+			h.encode(&test.record)
+			if h.buf.String() != test.expected {
+				t.Errorf("message expected %q, but got %q", test.expected, h.buf.String())
+			}
+		})
+	}
+}
+
+func Test_SyslogHandler_encodeRFC3164(t *testing.T) {
+	t.Parallel()
+
+	tm := time.Date(2025, 3, 2, 15, 7, 50, 348957000, time.UTC)
+	tests := []struct {
+		name     string
+		tag      string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "trace",
+			tag:      "app",
+			record:   Record{Time: tm, Level: LevelTrace, Message: "traced"},
+			expected: "<135>Mar  2 15:07:50 host app[42]: traced",
+		},
+		{
+			name:     "panic",
+			tag:      "app",
+			record:   Record{Time: tm, Level: LevelPanic, Message: "panicked"},
+			expected: "<130>Mar  2 15:07:50 host app[42]: panicked",
+		},
+		{
+			name:     "fatal",
+			tag:      "app",
+			record:   Record{Time: tm, Level: LevelFatal, Message: "exited"},
+			expected: "<128>Mar  2 15:07:50 host app[42]: exited",
+		},
+		{
+			name: "labels",
+			tag:  "my app:",
+			record: Record{
+				Time: tm, Level: LevelError, Message: "failed\nto update\n",
+				Labels: []string{"user=1000", "worker"}, Fields: []Field{Int("id", 1)},
+			},
+			expected: `<131>Mar  2 15:07:50 host my_app_[42]: failed to update user=1000 worker id=1`,
+		},
+		{
+			name:     "truncated",
+			tag:      "app",
+			record:   Record{Time: tm, Level: LevelInfo, Message: strings.Repeat("a", 987) + "ąąą"},
+			expected: "<134>Mar  2 15:07:50 host app[42]: " + strings.Repeat("a", 987) + "ą",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testSyslogOpts
+			opts.Format = SyslogRFC3164
+			opts.Tag = test.tag
+			h := &SyslogHandler{opts: opts}
+			h.encode(&test.record)
+			if h.buf.String() != test.expected {
+				t.Errorf("message expected %q, but got %q", test.expected, h.buf.String())
+			}
+			if h.buf.Len() > rfc3164MaxLen {
+				t.Errorf("message length expected up to %d, but got %d", rfc3164MaxLen, h.buf.Len())
+			}
+		})
+	}
+}
+
+func Test_DialSyslog_udp(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer conn.Close()
+
+	h, err := DialSyslog("udp", conn.LocalAddr().String(), testSyslogOpts)
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+
+	logger := WithLabels(New(CustomHandler(h)), "user=1000")
+	logger.Info("first")
+	logger.Error("second")
+
+	for _, expected := range []string{`[labels@32473 user="1000"] first`, `[labels@32473 user="1000"] second`} {
+		msg := readSyslogPacket(t, conn)
+		if !strings.HasPrefix(msg, "<1") || !strings.HasSuffix(msg, expected) {
+			t.Errorf("message expected to end with %q, but got %q", expected, msg)
+		}
+	}
+}
+
+func Test_DialSyslog_unixgram(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer conn.Close()
+
+	h, err := DialSyslog("unixgram", path, testSyslogOpts)
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+
+	New(CustomHandler(h)).Warn("message")
+	if msg := readSyslogPacket(t, conn); !strings.HasPrefix(msg, "<132>1 ") || !strings.HasSuffix(msg, " host app 42 - - message") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func Test_DialSyslog_tcp(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer listener.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	h, err := DialSyslog("tcp", listener.Addr().String(), testSyslogOpts)
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+	logger := New(CustomHandler(h))
+
+	first := <-conns
+	defer first.Close()
+	firstReader := bufio.NewReader(first)
+	logger.Info("first")
+	logger.Info("second")
+	for _, expected := range []string{"first", "second"} {
+		if msg := readSyslogFrame(t, firstReader); !strings.HasSuffix(msg, " - - "+expected) {
+			t.Errorf("message expected to end with %q, but got %q", expected, msg)
+		}
+	}
+
+	// the broken connection is replaced by a new one
+	h.conn.Close()
+	logger.Info("reconnected")
+	second := <-conns
+	defer second.Close()
+	if msg := readSyslogFrame(t, bufio.NewReader(second)); !strings.HasSuffix(msg, " - - reconnected") {
+		t.Errorf("message expected to end with %q, but got %q", "reconnected", msg)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if err := h.Handle(Record{Level: LevelInfo}); err != ErrClosed {
+		t.Errorf("error expected %v, but got %v", ErrClosed, err)
+	}
+}
+
+func readSyslogPacket(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+
+	buf := make([]byte, 2048)
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("failed to set deadline: %s", err)
+	}
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	return string(buf[:n])
+}
+
+// readSyslogFrame reads an octet-counted message.
+func readSyslogFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("failed to read length: %s", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		t.Fatalf("invalid length %q: %s", length, err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("failed to read message: %s", err)
+	}
+	return string(buf)
+}
+
+func Test_DialSyslog_tcpRFC3164(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer listener.Close()
+
+	opts := testSyslogOpts
+	opts.Format = SyslogRFC3164
+	h, err := DialSyslog("tcp", listener.Addr().String(), opts)
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer h.Close()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("failed to accept: %s", err)
+	}
+	defer conn.Close()
+
+	New(CustomHandler(h)).Info("message")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if !strings.HasPrefix(line, "<134>") || !strings.HasSuffix(line, " host app[42]: message\n") {
+		t.Errorf("unexpected message %q", line)
+	}
+}
@@ -0,0 +1,155 @@
+package log
+
+import (
+	"errors"
+	"slices"
+)
+
+// SinkOpts is an output of a logger with its own minimum level, see Tee.
+type SinkOpts struct {
+	// MinLevel is the most verbose level passed to the sink, zero passes all the records of the logger.
+	MinLevel Level
+	// MaxLevel is the most severe level passed to the sink, zero passes records up to fatal.
+	MaxLevel Level
+	// Opts are applied on top of the logger options to build the sink output.
+	Opts []Opt
+
+	// levelWriter tells that the sink is created by LevelWriter
+	levelWriter bool
+}
+
+// Sink creates an output of Tee, which gets records up to the minimum level.
+//
+// The sink is built by the logger options updated with the sink options, so it can have
+// its own encoding, format, flags and writer. Only the output options are used:
+// Flags, JSON, Logfmt, Format, Writer, CustomLogger, CustomHandler and Async.
+//
+//	Example: log.Sink(log.LevelError, log.Writer(file), log.JSON())
+func Sink(minLevel Level, opts ...Opt) SinkOpts {
+	return SinkOpts{
+		MinLevel: minLevel,
+		Opts:     opts,
+	}
+}
+
+// teeHandler passes records to all the sinks allowing the record level,
+// an error of one sink does not prevent other sinks from handling the record.
+//
+// Records of levels not covered by level writers are passed to the fallback handler, when it is set.
+type teeHandler struct {
+	sinks    []teeSink
+	fallback Handler
+	covered  [LevelTrace + 1]bool
+}
+
+type teeSink struct {
+	minLevel Level
+	maxLevel Level
+	handler  Handler
+}
+
+func (h *teeHandler) Handle(r Record) error {
+	var errs []error
+	for _, sink := range h.sinks {
+		if sink.minLevel < r.Level || r.Level < sink.maxLevel {
+			continue
+		}
+		if err := sink.handler.Handle(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if h.fallback != nil && !h.covered[normalizeLevel(r.Level)] {
+		if err := h.fallback.Handle(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync syncs all the sinks.
+func (h *teeHandler) Sync() error {
+	var errs []error
+	for _, sink := range h.sinks {
+		errs = append(errs, syncHandler(sink.handler))
+	}
+	if h.fallback != nil {
+		errs = append(errs, syncHandler(h.fallback))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all the sinks.
+func (h *teeHandler) Close() error {
+	var errs []error
+	for _, sink := range h.sinks {
+		errs = append(errs, closeHandler(sink.handler))
+	}
+	if h.fallback != nil {
+		errs = append(errs, closeHandler(h.fallback))
+	}
+	return errors.Join(errs...)
+}
+
+// formatHandler prints records by the sink format instead of the logger format.
+type formatHandler struct {
+	format  format
+	handler Handler
+}
+
+func (h *formatHandler) Handle(r Record) error {
+	r.format = &h.format
+	return h.handler.Handle(r)
+}
+
+func (h *formatHandler) Sync() error {
+	return syncHandler(h.handler)
+}
+
+func (h *formatHandler) Close() error {
+	return closeHandler(h.handler)
+}
+
+func buildTeeHandler(opts *Opts) Handler {
+	h := &teeHandler{sinks: make([]teeSink, 0, len(opts.Sinks))}
+	levelWriters := false
+	for _, sink := range opts.Sinks {
+		sinkOpts := *opts
+		sinkOpts.LevelNames = copyLevelNames(opts.LevelNames)
+		sinkOpts.Writer = nil
+		sinkOpts.Logger = nil
+		sinkOpts.Handler = nil
+		sinkOpts.Async = nil
+		sinkOpts.Sinks = nil
+		for _, opt := range sink.Opts {
+			opt(&sinkOpts)
+		}
+
+		handler := buildHandler(&sinkOpts)
+		if sinkOpts.Format != opts.Format {
+			handler = &formatHandler{format: buildFormat(sinkOpts.Format, false), handler: handler}
+		}
+		minLevel, maxLevel := LevelTrace, LevelFatal
+		if sink.MinLevel != 0 {
+			minLevel = normalizeLevel(sink.MinLevel)
+		}
+		if sink.MaxLevel != 0 {
+			maxLevel = normalizeLevel(sink.MaxLevel)
+		}
+		h.sinks = append(h.sinks, teeSink{minLevel: minLevel, maxLevel: maxLevel, handler: handler})
+		if sink.levelWriter {
+			levelWriters = true
+			for level := maxLevel; level <= minLevel; level++ {
+				h.covered[level] = true
+			}
+		}
+	}
+
+	// records of levels without a level writer are written to the logger writer, so they are not lost
+	if levelWriters && slices.Contains(h.covered[LevelFatal:], false) {
+		fallbackOpts := *opts
+		fallbackOpts.Async = nil
+		fallbackOpts.Sinks = nil
+		h.fallback = buildHandler(&fallbackOpts)
+	}
+	return h
+}
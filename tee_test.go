@@ -0,0 +1,208 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func Test_Tee(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		sinks    func(buf *bytes.Buffer) []SinkOpts
+		expected []string
+	}{
+		{
+			name: "text",
+			sinks: func(buf *bytes.Buffer) []SinkOpts {
+				return []SinkOpts{Sink(LevelError, Writer(buf), Flags(0))}
+			},
+			expected: []string{"[error] user=1000 failed"},
+		},
+		{
+			name: "json",
+			sinks: func(buf *bytes.Buffer) []SinkOpts {
+				return []SinkOpts{Sink(LevelWarn, Writer(buf), Flags(0), JSON())}
+			},
+			expected: []string{
+				`{"level":"warn","msg":"slow","labels":["user=1000"]}`,
+				`{"level":"error","msg":"failed","labels":["user=1000"]}`,
+			},
+		},
+		{
+			name: "format",
+			sinks: func(buf *bytes.Buffer) []SinkOpts {
+				return []SinkOpts{Sink(LevelInfo, Writer(buf), Flags(0), Format("${level}: ${msg} ${labels}"))}
+			},
+			expected: []string{"info: started user=1000", "warn: slow user=1000", "error: failed user=1000"},
+		},
+		{
+			name: "failing-sink",
+			sinks: func(buf *bytes.Buffer) []SinkOpts {
+				return []SinkOpts{
+					Sink(LevelDebug, Writer(failingWriter{}), Logfmt()),
+					Sink(LevelError, Writer(buf), Flags(0)),
+				}
+			},
+			expected: []string{"[error] user=1000 failed"},
+		},
+		{
+			name: "zero-level",
+			sinks: func(buf *bytes.Buffer) []SinkOpts {
+				return []SinkOpts{{Opts: []Opt{Writer(buf), Flags(0)}}}
+			},
+			expected: []string{"[debug] user=1000 checked", "[info] user=1000 started", "[warn] user=1000 slow", "[error] user=1000 failed"},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			ring := NewRingBuffer(0)
+			logger := WithLabels(New(DebugLevel(), Tee(append(test.sinks(buf), Sink(LevelDebug, CustomHandler(ring)))...)), "user=1000")
+			logger.Trace("ignored")
+			logger.Debug("checked")
+			logger.Info("started")
+			logger.Warn("slow")
+			logger.Error("failed")
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if strings.Join(lines, "\n") != strings.Join(test.expected, "\n") {
+				t.Errorf("lines expected %q, but got %q", test.expected, lines)
+			}
+			if records := ring.Records(); len(records) != 4 {
+				t.Errorf("ring buffer expected to keep %d records, but got %d", 4, len(records))
+			}
+		})
+	}
+}
+
+func Test_teeHandler_errors(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	handler := buildTeeHandler(mergeOpts(defaultOpts(), &Opts{
+		Sinks: []SinkOpts{
+			Sink(LevelInfo, Writer(failingWriter{})),
+			Sink(LevelInfo, Writer(buf)),
+		},
+	}))
+	err := handler.Handle(Record{Level: LevelInfo, LevelName: LevelNameInfo, Message: "message"})
+	if err == nil || err.Error() != "write failed" {
+		t.Errorf("error expected %q, but got %v", "write failed", err)
+	}
+	if !strings.Contains(buf.String(), "message") {
+		t.Errorf("record expected to be written by other sinks, but got %q", buf.String())
+	}
+}
+
+func Test_Tee_Sync_Close(t *testing.T) {
+	t.Parallel()
+
+	first, second := &testWriter{}, &testWriter{}
+	logger := New(Tee(
+		Sink(LevelInfo, Writer(first)),
+		Sink(LevelInfo, Writer(second), JSON(), Async(AsyncOpts{})),
+	))
+	logger.Info("message")
+	if err := Sync(logger); err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+	if first.synced != 1 || second.synced != 1 {
+		t.Errorf("sinks expected to be synced once, but got %d and %d", first.synced, second.synced)
+	}
+	if second.Len() == 0 {
+		t.Errorf("async sink records expected to be written on sync")
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if first.closed != 1 || second.closed != 1 {
+		t.Errorf("sinks expected to be closed once, but got %d and %d", first.closed, second.closed)
+	}
+}
+
+func Test_LevelWriter(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New(
+		TraceLevel(), Flags(0),
+		LevelWriter(stdout, LevelInfo, LevelTrace),
+		LevelWriter(stderr, LevelFatal, LevelWarn),
+	)
+	logger.Trace("traced")
+	logger.Info("started")
+	logger.Warn("slow")
+	logger.Error("failed")
+
+	if expected := "[trace] traced\n[info] started\n"; stdout.String() != expected {
+		t.Errorf("stdout expected %q, but got %q", expected, stdout.String())
+	}
+	if expected := "[warn] slow\n[error] failed\n"; stderr.String() != expected {
+		t.Errorf("stderr expected %q, but got %q", expected, stderr.String())
+	}
+}
+
+func Test_LevelWriter_uncovered(t *testing.T) {
+	t.Parallel()
+
+	out, stdout := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New(
+		TraceLevel(), Flags(0), Writer(out),
+		LevelWriter(stdout, LevelTrace, LevelInfo),
+	)
+	logger.Info("started")
+	logger.Warn("slow")
+	logger.Error("failed")
+
+	if expected := "[info] started\n"; stdout.String() != expected {
+		t.Errorf("stdout expected %q, but got %q", expected, stdout.String())
+	}
+	if expected := "[warn] slow\n[error] failed\n"; out.String() != expected {
+		t.Errorf("writer expected %q, but got %q", expected, out.String())
+	}
+}
+
+func Test_StdStreams(t *testing.T) {
+	t.Parallel()
+
+	opts := defaultOpts()
+	StdStreams()(opts)
+
+	handler, ok := buildHandler(opts).(*teeHandler)
+	if !ok {
+		t.Fatalf("tee handler expected, but got %T", buildHandler(opts))
+	}
+	expected := []teeSink{
+		{minLevel: LevelTrace, maxLevel: LevelInfo},
+		{minLevel: LevelWarn, maxLevel: LevelFatal},
+	}
+	if len(handler.sinks) != len(expected) {
+		t.Fatalf("sinks expected %d, but got %d", len(expected), len(handler.sinks))
+	}
+	for i, sink := range handler.sinks {
+		if sink.minLevel != expected[i].minLevel || sink.maxLevel != expected[i].maxLevel {
+			t.Errorf("sink %d levels expected %d-%d, but got %d-%d", i, expected[i].minLevel, expected[i].maxLevel, sink.minLevel, sink.maxLevel)
+		}
+	}
+	if writer := handler.sinks[0].handler.(*stdHandler).logger.Writer(); writer != os.Stdout {
+		t.Errorf("stdout writer expected, but got %#v", writer)
+	}
+	if writer := handler.sinks[1].handler.(*stdHandler).logger.Writer(); writer != os.Stderr {
+		t.Errorf("stderr writer expected, but got %#v", writer)
+	}
+}
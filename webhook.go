@@ -0,0 +1,215 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultWebhookTimeout   = 10 * time.Second
+	defaultWebhookRateLimit = time.Second
+	defaultWebhookTemplate  = "{{range .}}{{.Text}}{{end}}"
+
+	webhookJSONFlags = log.LstdFlags | log.Lmicroseconds | log.Lshortfile
+)
+
+// WebhookFormat is the payload format of a WebhookHandler.
+type WebhookFormat int
+
+const (
+	// WebhookJSON posts a JSON array of the records in the JSON format,
+	// or the template output as it is, when the template is set.
+	WebhookJSON WebhookFormat = iota
+	// WebhookSlack posts a Slack message `{"text":"..."}` with the template output.
+	WebhookSlack
+	// WebhookTeams posts a Microsoft Teams message card with the template output.
+	WebhookTeams
+)
+
+// WebhookOpts configures a WebhookHandler, zero values are replaced by defaults.
+type WebhookOpts struct {
+	// Format is the payload format.
+	//
+	//	Default: WebhookJSON
+	Format WebhookFormat
+	// Template is a text/template of the message text executed with the batch records, []Record,
+	// the template output is the whole body for WebhookJSON.
+	// `json` function encodes a value as JSON, e.g. `{{json .Message}}`.
+	//
+	//	Default: {{range .}}{{.Text}}{{end}}
+	Template string
+	// MinLevel is the lowest level of the records sent, the records are filtered by the logger level too.
+	//
+	//	Default: LevelError
+	MinLevel Level
+	// RateLimit is the minimum interval between requests, records are batched meanwhile.
+	//
+	//	Default: 1s
+	RateLimit time.Duration
+	// Header is added to the requests.
+	Header http.Header
+	// Client sends the requests.
+	//
+	//	Default: http.Client with 10s timeout
+	Client *http.Client
+	// Batch configures batching of the records.
+	Batch BatchOpts
+	// Retry configures retries of batches which failed to be sent,
+	// requests rejected with 4xx status except 429 are not retried.
+	Retry RetryOpts
+}
+
+// WebhookHandler is a Handler that posts records to a webhook, e.g. for alerting to a chat:
+//
+//	{"text":"[error] failed user=1000\n"}
+//
+// Records are sent in background as batches, so a slow webhook never blocks logging,
+// the records are dropped, when too many of them are pending.
+// Sync waits for the pending records to be sent. WebhookHandler is safe for concurrent use.
+type WebhookHandler struct {
+	batcher  *batcher
+	url      string
+	opts     WebhookOpts
+	template *template.Template
+
+	// last and buffers are used by the batcher goroutine only
+	last time.Time
+	buf  bytes.Buffer
+	text bytes.Buffer
+}
+
+// NewWebhookHandler creates a handler posting records to the webhook URL.
+func NewWebhookHandler(webhookURL string, opts WebhookOpts) (*WebhookHandler, error) {
+	if _, err := url.ParseRequestURI(webhookURL); err != nil {
+		return nil, err
+	}
+	if opts.MinLevel == 0 {
+		opts.MinLevel = LevelError
+	}
+	opts.MinLevel = normalizeLevel(opts.MinLevel)
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = defaultWebhookRateLimit
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+
+	h := &WebhookHandler{
+		url:  webhookURL,
+		opts: opts,
+	}
+	if opts.Template != "" || opts.Format != WebhookJSON {
+		text := opts.Template
+		if text == "" {
+			text = defaultWebhookTemplate
+		}
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": webhookJSON}).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		h.template = tmpl
+	}
+	h.batcher = newBatcher(opts.Batch, opts.Retry, h.send)
+	return h, nil
+}
+
+// Handle adds the record to the batch, when its level is MinLevel or higher.
+func (h *WebhookHandler) Handle(r Record) error {
+	if r.Level > h.opts.MinLevel {
+		return nil
+	}
+	return h.batcher.handle(r)
+}
+
+// Sync waits for the pending records to be sent and returns the last error of sending.
+func (h *WebhookHandler) Sync() error {
+	return h.batcher.flush()
+}
+
+// Close sends the pending records and stops sending.
+func (h *WebhookHandler) Close() error {
+	return h.batcher.close()
+}
+
+// Dropped returns the number of records dropped, because there were too many pending records.
+func (h *WebhookHandler) Dropped() uint64 {
+	return h.batcher.dropped.Load()
+}
+
+func (h *WebhookHandler) send(records []Record) error {
+	if err := h.encode(records); err != nil {
+		return &permanentError{err}
+	}
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(h.buf.Bytes()))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for key, values := range h.opts.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if wait := time.Until(h.last.Add(h.opts.RateLimit)); wait > 0 {
+		time.Sleep(wait)
+	}
+	h.last = time.Now()
+	return doRequest(h.opts.Client, req, nil)
+}
+
+func (h *WebhookHandler) encode(records []Record) error {
+	h.buf.Reset()
+	if h.template == nil {
+		h.buf.WriteByte('[')
+		for i := range records {
+			if i > 0 {
+				h.buf.WriteByte(',')
+			}
+			encodeJSON(&h.buf, &records[i], webhookJSONFlags)
+			h.buf.Truncate(h.buf.Len() - 1)
+		}
+		h.buf.WriteByte(']')
+		return nil
+	}
+
+	if h.opts.Format == WebhookJSON {
+		if err := h.template.Execute(&h.buf, records); err != nil {
+			return err
+		}
+		if !json.Valid(h.buf.Bytes()) {
+			return fmt.Errorf("log: webhook template output is not valid JSON: %.100s", h.buf.Bytes())
+		}
+		return nil
+	}
+	h.text.Reset()
+	if err := h.template.Execute(&h.text, records); err != nil {
+		return err
+	}
+	text := strings.TrimRight(h.text.String(), "\n")
+	if h.opts.Format == WebhookTeams {
+		// Teams renders the text as markdown, where a single new line is not a line break
+		h.buf.WriteString(`{"@type":"MessageCard","@context":"https://schema.org/extensions","summary":`)
+		appendJSONString(&h.buf, records[0].Message)
+		h.buf.WriteString(`,"text":`)
+		appendJSONString(&h.buf, strings.ReplaceAll(text, "\n", "\n\n"))
+		h.buf.WriteByte('}')
+		return nil
+	}
+	h.buf.WriteString(`{"text":`)
+	appendJSONString(&h.buf, text)
+	h.buf.WriteByte('}')
+	return nil
+}
+
+// webhookJSON encodes the value as JSON for templates.
+func webhookJSON(value any) string {
+	var buf bytes.Buffer
+	appendJSONValue(&buf, value)
+	return buf.String()
+}
@@ -0,0 +1,260 @@
+package log
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer records request bodies and their times and responds with the statuses in order,
+// the last status is repeated.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	times    []time.Time
+	statuses []int
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	t.Helper()
+
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.times = append(s.times, time.Now())
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status = s.statuses[0]
+			if len(s.statuses) > 1 {
+				s.statuses = s.statuses[1:]
+			}
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) requests() ([]string, []time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies, s.times
+}
+
+func Test_WebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	// the time and the caller depend on the test run
+	jsonReplacer := regexp.MustCompile(`"time":"[^"]+","level"|"caller":"webhook_test.go:\d+"`)
+
+	tests := []struct {
+		name     string
+		opts     WebhookOpts
+		expected string
+	}{
+		{
+			name:     "json",
+			opts:     WebhookOpts{},
+			expected: `[{"time","level":"error","caller","msg":"failed","labels":["env=prod"],"user":1000},{"time","level":"fatal","caller","msg":"stopped","labels":["env=prod"]}]`,
+		},
+		{
+			name: "json-template",
+			opts: WebhookOpts{
+				Template: `{"alerts":[{{range $i, $r := .}}{{if $i}},{{end}}{{json $r.Message}}{{end}}]}`,
+			},
+			expected: `{"alerts":["failed","stopped"]}`,
+		},
+		{
+			name:     "slack",
+			opts:     WebhookOpts{Format: WebhookSlack},
+			expected: `{"text":"[error] env=prod user=1000 failed\n[fatal] env=prod stopped"}`,
+		},
+		{
+			name: "slack-template",
+			opts: WebhookOpts{
+				Format:   WebhookSlack,
+				Template: `{{len .}} alerts:{{range .}} *{{.LevelName}}* {{.Message}}{{end}}`,
+			},
+			expected: `{"text":"2 alerts: *error* failed *fatal* stopped"}`,
+		},
+		{
+			name:     "teams",
+			opts:     WebhookOpts{Format: WebhookTeams},
+			expected: `{"@type":"MessageCard","@context":"https://schema.org/extensions","summary":"failed","text":"[error] env=prod user=1000 failed\n\n[fatal] env=prod stopped"}`,
+		},
+		{
+			name:     "warn-level",
+			opts:     WebhookOpts{Format: WebhookSlack, MinLevel: LevelWarn},
+			expected: `{"text":"[warn] env=prod slow\n[error] env=prod user=1000 failed\n[fatal] env=prod stopped"}`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newWebhookServer(t)
+			opts := test.opts
+			opts.RateLimit = time.Millisecond
+			opts.Batch = BatchOpts{Interval: time.Hour}
+			h, err := NewWebhookHandler(server.URL, opts)
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			logger := WithLabels(New(CustomHandler(h), ExitFunc(func(int) {})), "env=prod")
+			logger.Info("started")
+			logger.Warn("slow")
+			logger.Errorw("failed", "user", 1000)
+			logger.Fatal("stopped")
+			if err := Close(logger); err != nil {
+				t.Fatalf("failed to close: %s", err)
+			}
+
+			bodies, _ := server.requests()
+			if len(bodies) != 1 {
+				t.Fatalf("1 request expected, but got %d: %v", len(bodies), bodies)
+			}
+			body := jsonReplacer.ReplaceAllStringFunc(bodies[0], func(s string) string {
+				if s[1] == 't' {
+					return `"time","level"`
+				}
+				return `"caller"`
+			})
+			if body != test.expected {
+				t.Errorf("body expected %s, but got %s", test.expected, bodies[0])
+			}
+		})
+	}
+}
+
+func Test_WebhookHandler_rateLimit(t *testing.T) {
+	t.Parallel()
+
+	const rateLimit = 50 * time.Millisecond
+	server := newWebhookServer(t)
+	h, err := NewWebhookHandler(server.URL, WebhookOpts{
+		RateLimit: rateLimit,
+		Batch:     BatchOpts{Size: 1},
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %s", err)
+	}
+	defer h.Close()
+
+	logger := New(CustomHandler(h))
+	start := time.Now()
+	for range 3 {
+		logger.Error("failed")
+	}
+	if elapsed := time.Since(start); elapsed >= rateLimit {
+		t.Errorf("logging expected not to wait for the webhook, but it took %s", elapsed)
+	}
+	if err := h.Sync(); err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+
+	_, times := server.requests()
+	if len(times) != 3 {
+		t.Fatalf("3 requests expected, but got %d", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if interval := times[i].Sub(times[i-1]); interval < rateLimit-5*time.Millisecond {
+			t.Errorf("interval between requests expected at least %s, but got %s", rateLimit, interval)
+		}
+	}
+}
+
+func Test_WebhookHandler_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     WebhookOpts
+		statuses []int
+		requests int
+	}{
+		{
+			name:     "retries-exceeded",
+			opts:     WebhookOpts{},
+			statuses: []int{http.StatusServiceUnavailable},
+			requests: 3,
+		},
+		{
+			name:     "rejected",
+			opts:     WebhookOpts{},
+			statuses: []int{http.StatusNotFound},
+			requests: 1,
+		},
+		{
+			name:     "invalid-json-template",
+			opts:     WebhookOpts{Template: `{{range .}}{{.Message}}{{end}}`},
+			requests: 0,
+		},
+		{
+			name:     "failing-template",
+			opts:     WebhookOpts{Format: WebhookSlack, Template: `{{.Message}}`},
+			requests: 0,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newWebhookServer(t, test.statuses...)
+			opts := test.opts
+			opts.RateLimit = time.Millisecond
+			opts.Batch = BatchOpts{Interval: time.Hour}
+			opts.Retry = RetryOpts{MaxRetries: 2, MinBackoff: time.Millisecond}
+			h, err := NewWebhookHandler(server.URL, opts)
+			if err != nil {
+				t.Fatalf("failed to create handler: %s", err)
+			}
+			defer h.Close()
+
+			New(CustomHandler(h)).Error("failed")
+			if err := h.Sync(); err == nil {
+				t.Error("failing sync expected")
+			}
+			if bodies, _ := server.requests(); len(bodies) != test.requests {
+				t.Errorf("requests expected %d, but got %d", test.requests, len(bodies))
+			}
+		})
+	}
+}
+
+func Test_NewWebhookHandler_invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		url  string
+		opts WebhookOpts
+	}{
+		{name: "url", url: "hooks", opts: WebhookOpts{}},
+		{name: "template", url: "http://localhost/hooks", opts: WebhookOpts{Template: "{{range .}}"}},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := NewWebhookHandler(test.url, test.opts); err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+}